@@ -0,0 +1,28 @@
+//go:build go1.23
+
+package cache
+
+import (
+	"iter"
+	"time"
+)
+
+// All returns an iterator over keys and values of the items in the cache
+// which have not expired. See Range for details.
+func (c *Cache[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		c.Range(func(key K, val V, _ time.Time) bool {
+			return yield(key, val)
+		})
+	}
+}
+
+// All returns an iterator over keys and values of the items in the cache
+// which have not expired. The order is relied on algorithms within each shard.
+func (c *ShardedCache[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		c.Range(func(key K, val V, _ time.Time) bool {
+			return yield(key, val)
+		})
+	}
+}
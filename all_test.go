@@ -0,0 +1,41 @@
+//go:build go1.23
+
+package cache_test
+
+import (
+	"testing"
+
+	cache "github.com/Code-Hex/go-generics-cache"
+)
+
+func TestAll(t *testing.T) {
+	c := cache.New(cache.AsLRU[string, int]())
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+
+	var keys []string
+	sum := 0
+	for key, val := range c.All() {
+		keys = append(keys, key)
+		sum += val
+		if key == "b" {
+			break
+		}
+	}
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "b" || sum != 3 {
+		t.Errorf("want a and b but got %v, sum %d", keys, sum)
+	}
+
+	sharded := cache.NewSharded[int, int](4)
+	for i := 0; i < 10; i++ {
+		sharded.Set(i, i)
+	}
+	n := 0
+	for range sharded.All() {
+		n++
+	}
+	if n != 10 {
+		t.Errorf("want 10 items but got %d", n)
+	}
+}
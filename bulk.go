@@ -0,0 +1,126 @@
+package cache
+
+import "time"
+
+// batchSetter is implemented by cache policies which are able to set
+// multiple values faster than calling Set for each.
+type batchSetter[K comparable, V any] interface {
+	SetMany(items map[K]V)
+}
+
+// batchDeleter is implemented by cache policies which are able to delete
+// multiple keys faster than calling Delete for each.
+type batchDeleter[K comparable] interface {
+	DeleteMany(keys []K)
+}
+
+// SetMany sets multiple values to the cache with the same options, replacing
+// any existing values. It is equivalent to calling Set for each item, but the
+// cache is locked only once.
+//
+// It returns the keys of the items which have not been stored, in no particular
+// order. An item is not stored if the cache policy rejects it (e.g. its cost
+// exceeds the max cost) or evicts it to make room for the other items.
+//
+// If the cache has been closed, all keys are reported as rejected.
+func (c *Cache[K, V]) SetMany(items map[K]V, opts ...ItemOption) (rejected []K) {
+	c.mu.Lock()
+	defer c.unlock()
+	if c.closed {
+		for key := range items {
+			rejected = append(rejected, key)
+		}
+		return rejected
+	}
+	newItems := make(map[K]*Item[K, V], len(items))
+	for key, val := range items {
+		c.replacing(key)
+		item := c.newItem(key, val, opts...)
+		if !item.hasExpiration() {
+			c.expManager.remove(key)
+		}
+		newItems[key] = item
+	}
+	if s, ok := c.cache.(batchSetter[K, *Item[K, V]]); ok {
+		s.SetMany(newItems)
+	} else {
+		for key, item := range newItems {
+			c.cache.Set(key, item)
+		}
+	}
+
+	// registers the expirations after setting all items, because setting an
+	// item may evict another item of the batch.
+	keys := make([]K, 0, len(newItems))
+	expirations := make([]time.Time, 0, len(newItems))
+	for key, item := range newItems {
+		if cur, ok := c.cache.Peek(key); !ok || cur != item {
+			rejected = append(rejected, key)
+			continue
+		}
+		if item.hasExpiration() {
+			keys = append(keys, key)
+			expirations = append(expirations, item.staleUntil())
+		}
+	}
+	if len(keys) > 0 {
+		c.expManager.updateMany(keys, expirations)
+	}
+	return rejected
+}
+
+// GetMany looks up values of the keys from the cache while the cache is
+// locked only once. It returns the values which have been found, and the
+// keys which are not present or have expired in the order of keys.
+//
+// If the cache has been closed, all keys are reported as missing.
+func (c *Cache[K, V]) GetMany(keys []K) (found map[K]V, missing []K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	found = make(map[K]V, len(keys))
+	if c.closed {
+		return found, append(missing, keys...)
+	}
+	for _, key := range keys {
+		if val, ok := c.get(key); ok {
+			found[key] = val
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	return found, missing
+}
+
+// DeleteMany deletes the items with provided keys from the cache while the
+// cache is locked only once. It returns the keys which have been deleted.
+//
+// It does nothing if the cache has been closed.
+func (c *Cache[K, V]) DeleteMany(keys []K) (deleted []K) {
+	c.mu.Lock()
+	defer c.unlock()
+	if c.closed {
+		return nil
+	}
+	seen := make(map[K]struct{}, len(keys))
+	for _, key := range keys {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		item, ok := c.cache.Peek(key)
+		if !ok {
+			continue
+		}
+		c.evicted(key, item, EvictionReasonDeleted)
+		c.expManager.remove(key)
+		deleted = append(deleted, key)
+	}
+	if d, ok := c.cache.(batchDeleter[K]); ok {
+		d.DeleteMany(deleted)
+		return deleted
+	}
+	for _, key := range deleted {
+		c.cache.Delete(key)
+	}
+	return deleted
+}
@@ -0,0 +1,143 @@
+package cache_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	cache "github.com/Code-Hex/go-generics-cache"
+	"github.com/Code-Hex/go-generics-cache/cachetest"
+	"github.com/Code-Hex/go-generics-cache/policy/lfu"
+	"github.com/Code-Hex/go-generics-cache/policy/lru"
+)
+
+func TestBulk(t *testing.T) {
+	cases := []struct {
+		name string
+		opts []cache.Option[string, int]
+	}{
+		{
+			name: "Simple",
+		},
+		{
+			name: "LRU",
+			opts: []cache.Option[string, int]{cache.AsLRU[string, int](lru.WithCapacity(10))},
+		},
+		{
+			name: "LFU",
+			opts: []cache.Option[string, int]{cache.AsLFU[string, int](lfu.WithCapacity(10))},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			clk := cachetest.NewFakeClock(time.Now())
+			var evicted []string
+			c := cache.New(append(tc.opts,
+				cache.WithClock[string, int](clk),
+				cache.OnEvicted(func(key string, _ int, reason cache.EvictionReason) {
+					evicted = append(evicted, key+":"+reason.String())
+				}),
+			)...)
+			c.Set("a", 0)
+			if rejected := c.SetMany(map[string]int{"a": 1, "b": 2, "c": 3}, cache.WithExpiration(time.Minute)); len(rejected) != 0 {
+				t.Fatalf("want no rejected keys but got %v", rejected)
+			}
+			c.SetMany(map[string]int{"d": 4})
+			if got := c.Len(); got != 4 {
+				t.Fatalf("want 4 items but got %d", got)
+			}
+
+			found, missing := c.GetMany([]string{"a", "x", "d", "y"})
+			if want := map[string]int{"a": 1, "d": 4}; !reflect.DeepEqual(want, found) {
+				t.Errorf("want found %v but got %v", want, found)
+			}
+			if want := []string{"x", "y"}; !reflect.DeepEqual(want, missing) {
+				t.Errorf("want missing %v but got %v", want, missing)
+			}
+
+			// the expired items are missing, and deleted by the janitor.
+			clk.Advance(2 * time.Minute)
+			found, missing = c.GetMany([]string{"a", "b", "c", "d"})
+			if want := map[string]int{"d": 4}; !reflect.DeepEqual(want, found) {
+				t.Errorf("want found %v but got %v", want, found)
+			}
+			if want := []string{"a", "b", "c"}; !reflect.DeepEqual(want, missing) {
+				t.Errorf("want missing %v but got %v", want, missing)
+			}
+			if got := c.Len(); got != 1 {
+				t.Fatalf("want 1 item but got %d", got)
+			}
+
+			c.SetMany(map[string]int{"e": 5, "f": 6})
+			deleted := c.DeleteMany([]string{"d", "x", "e", "d"})
+			if want := []string{"d", "e"}; !reflect.DeepEqual(want, deleted) {
+				t.Errorf("want deleted %v but got %v", want, deleted)
+			}
+			if want, got := []string{"f"}, c.Keys(); !reflect.DeepEqual(want, got) {
+				t.Errorf("want keys %v but got %v", want, got)
+			}
+
+			sort.Strings(evicted)
+			want := []string{"a:expired", "a:replaced", "b:expired", "c:expired", "d:deleted", "e:deleted"}
+			if !reflect.DeepEqual(want, evicted) {
+				t.Errorf("want evicted %v but got %v", want, evicted)
+			}
+		})
+	}
+}
+
+func TestSetManyOverCapacity(t *testing.T) {
+	var evicted int
+	c := cache.New(
+		cache.AsLFU[int, int](lfu.WithCapacity(10)),
+		cache.OnEvicted(func(int, int, cache.EvictionReason) {
+			evicted++
+		}),
+	)
+	items := make(map[int]int, 15)
+	for i := 0; i < 15; i++ {
+		items[i] = i
+	}
+	rejected := c.SetMany(items, cache.WithExpiration(time.Hour))
+	if got := c.Len(); got != 10 {
+		t.Fatalf("want 10 items but got %d", got)
+	}
+	if evicted != 5 {
+		t.Errorf("want 5 evictions but got %d", evicted)
+	}
+	if got := len(rejected); got != 5 {
+		t.Fatalf("want 5 rejected keys but got %v", rejected)
+	}
+	for _, key := range rejected {
+		if c.Contains(key) {
+			t.Errorf("want rejected key %d is not stored", key)
+		}
+	}
+}
+
+func TestSetManyRejected(t *testing.T) {
+	c := cache.New(
+		cache.AsLRU[string, string](lru.WithMaxCost(10)),
+		cache.WithWeigher(func(_ string, val string) int64 {
+			return int64(len(val))
+		}),
+	)
+	rejected := c.SetMany(map[string]string{
+		"a":    "aaaa",
+		"huge": "hhhhhhhhhhh", // exceeds the max cost by itself
+	})
+	if want := []string{"huge"}; !reflect.DeepEqual(want, rejected) {
+		t.Errorf("want rejected %v but got %v", want, rejected)
+	}
+	if !c.Contains("a") || c.Contains("huge") {
+		t.Errorf("want only a is stored but got keys %v", c.Keys())
+	}
+
+	c.Close()
+	rejected = c.SetMany(map[string]string{"b": "b"})
+	if want := []string{"b"}; !reflect.DeepEqual(want, rejected) {
+		t.Errorf("want rejected %v after closed but got %v", want, rejected)
+	}
+}
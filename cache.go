@@ -5,18 +5,25 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Code-Hex/go-generics-cache/policy/arc"
 	"github.com/Code-Hex/go-generics-cache/policy/clock"
 	"github.com/Code-Hex/go-generics-cache/policy/fifo"
 	"github.com/Code-Hex/go-generics-cache/policy/lfu"
 	"github.com/Code-Hex/go-generics-cache/policy/lru"
 	"github.com/Code-Hex/go-generics-cache/policy/mru"
 	"github.com/Code-Hex/go-generics-cache/policy/simple"
+	"github.com/Code-Hex/go-generics-cache/policy/slru"
+	"github.com/Code-Hex/go-generics-cache/policy/tinylfu"
+	"github.com/Code-Hex/go-generics-cache/policy/twoq"
 )
 
 // Interface is a common-cache interface.
 type Interface[K comparable, V any] interface {
 	// Get looks up a key's value from the cache.
 	Get(key K) (value V, ok bool)
+	// Peek looks up a key's value from the cache without updating the
+	// state which the replacement policy relies on (e.g. recency, frequency).
+	Peek(key K) (value V, ok bool)
 	// Set sets a value to the cache with key. replacing any existing value.
 	Set(key K, val V)
 	// Keys returns the keys of the cache. The order is relied on algorithms.
@@ -25,6 +32,8 @@ type Interface[K comparable, V any] interface {
 	Delete(key K)
 	// Len returns the number of items in the cache.
 	Len() int
+	// Clear removes all items from the cache.
+	Clear()
 }
 
 var (
@@ -35,27 +44,133 @@ var (
 		(*fifo.Cache[struct{}, any])(nil),
 		(*mru.Cache[struct{}, any])(nil),
 		(*clock.Cache[struct{}, any])(nil),
+		(*arc.Cache[struct{}, any])(nil),
+		(*tinylfu.Cache[struct{}, any])(nil),
+		(*slru.Cache[struct{}, any])(nil),
+		(*twoq.Cache[struct{}, any])(nil),
+	}
+	_ = []evictionNotifier[struct{}, any]{
+		(*lru.Cache[struct{}, any])(nil),
+		(*lfu.Cache[struct{}, any])(nil),
+		(*fifo.Cache[struct{}, any])(nil),
+		(*mru.Cache[struct{}, any])(nil),
+		(*clock.Cache[struct{}, any])(nil),
+		(*arc.Cache[struct{}, any])(nil),
+		(*tinylfu.Cache[struct{}, any])(nil),
+		(*slru.Cache[struct{}, any])(nil),
+		(*twoq.Cache[struct{}, any])(nil),
+	}
+	_ = []nowFuncSetter{
+		(*lfu.Cache[struct{}, any])(nil),
+	}
+	_ = []resizer{
+		(*lru.Cache[struct{}, any])(nil),
+		(*lfu.Cache[struct{}, any])(nil),
+		(*fifo.Cache[struct{}, any])(nil),
+		(*mru.Cache[struct{}, any])(nil),
+		(*clock.Cache[struct{}, any])(nil),
+		(*slru.Cache[struct{}, any])(nil),
+		(*twoq.Cache[struct{}, any])(nil),
+	}
+	_ = []batchSetter[struct{}, any]{
+		(*lfu.Cache[struct{}, any])(nil),
+	}
+	_ = []batchDeleter[struct{}]{
+		(*lfu.Cache[struct{}, any])(nil),
 	}
 )
 
 // Item is an item
 type Item[K comparable, V any] struct {
-	Key                   K
-	Value                 V
-	Expiration            time.Time
+	Key        K
+	Value      V
+	Expiration time.Time
+	// SlidingExpiration is the duration of inactivity after which the item
+	// expires. Expiration is pushed forward by this duration on every access.
+	SlidingExpiration time.Duration
+	// MaxExpiration is the absolute time which Expiration is never pushed
+	// beyond by the sliding expiration. Zero means no limit.
+	MaxExpiration time.Time
+	// StaleWhileRevalidate is the grace period after Expiration during which
+	// GetOrLoad serves the expired value while reloading it in the background.
+	StaleWhileRevalidate time.Duration
+	// StaleIfError is the grace period after Expiration during which GetOrLoad
+	// falls back to the expired value if the loader returns an error.
+	StaleIfError          time.Duration
 	InitialReferenceCount int
+	Cost                  int64
+
+	// ttl is the time to live of the item which has been set with
+	// the expiration and without the sliding expiration.
+	ttl time.Duration
+	// clock is used to check the expiration. nil means the system clock.
+	clock Clock
 }
 
 func (item *Item[K, V]) hasExpiration() bool {
 	return !item.Expiration.IsZero()
 }
 
+// touch pushes forward the expiration of the item which has the sliding
+// expiration. It reports whether the expiration has been updated.
+func (item *Item[K, V]) touch() bool {
+	if item.SlidingExpiration <= 0 {
+		return false
+	}
+	item.Expiration = slidingExpiration(item.now(), item.SlidingExpiration, item.MaxExpiration)
+	return true
+}
+
+// slidingExpiration returns the expiration time after the sliding duration
+// from now, but not after max unless max is zero.
+func slidingExpiration(now time.Time, sliding time.Duration, max time.Time) time.Time {
+	exp := now.Add(sliding)
+	if !max.IsZero() && exp.After(max) {
+		return max
+	}
+	return exp
+}
+
 // Expired returns true if the item has expired.
 func (item *Item[K, V]) Expired() bool {
 	if !item.hasExpiration() {
 		return false
 	}
-	return nowFunc().After(item.Expiration)
+	return item.now().After(item.Expiration)
+}
+
+// staleUntil returns the time until which the item is retained after its
+// expiration to be served as a stale value.
+func (item *Item[K, V]) staleUntil() time.Time {
+	grace := item.StaleWhileRevalidate
+	if item.StaleIfError > grace {
+		grace = item.StaleIfError
+	}
+	return item.Expiration.Add(grace)
+}
+
+// discardable reports whether the item has expired and is no longer
+// retained to be served as a stale value.
+func (item *Item[K, V]) discardable() bool {
+	if !item.hasExpiration() {
+		return false
+	}
+	return item.now().After(item.staleUntil())
+}
+
+// withinGrace reports whether the item has expired no longer than d ago.
+func (item *Item[K, V]) withinGrace(d time.Duration) bool {
+	if d <= 0 || !item.Expired() {
+		return false
+	}
+	return !item.now().After(item.Expiration.Add(d))
+}
+
+func (item *Item[K, V]) now() time.Time {
+	if item.clock == nil {
+		return time.Now()
+	}
+	return item.clock.Now()
 }
 
 // GetReferenceCount returns reference count to be used when setting
@@ -64,14 +179,31 @@ func (item *Item[K, V]) GetReferenceCount() int {
 	return item.InitialReferenceCount
 }
 
-var nowFunc = time.Now
+// GetCost returns cost of the item to be used by cache policies
+// which limit the total cost (e.g., LRU with WithMaxCost option).
+func (item *Item[K, V]) GetCost() int64 {
+	return item.Cost
+}
 
 // ItemOption is an option for cache item.
 type ItemOption func(*itemOptions)
 
 type itemOptions struct {
-	expiration     time.Time // default none
-	referenceCount int
+	now               time.Time     // the time when the item is created
+	expiration        time.Time     // default none
+	slidingExpiration time.Duration // default none
+	referenceCount    int
+
+	staleWhileRevalidate time.Duration // default none
+	staleIfError         time.Duration // default none
+
+	keepExpiration bool // used by Compute
+
+	// defaultExpiration is true if the expiration is set by WithDefaultExpiration,
+	// and defaultSliding is true if the sliding expiration is set by
+	// WithDefaultSlidingExpiration.
+	defaultExpiration bool
+	defaultSliding    bool
 }
 
 // WithExpiration is an option to set expiration time for any items.
@@ -81,7 +213,33 @@ func WithExpiration(exp time.Duration) ItemOption {
 		return func(o *itemOptions) {}
 	}
 	return func(o *itemOptions) {
-		o.expiration = nowFunc().Add(exp)
+		o.expiration = o.now.Add(exp)
+		o.defaultExpiration = false
+	}
+}
+
+// WithNoExpiration is an option to make the item never expire, ignoring
+// the default expiration of the cache (e.g., WithDefaultExpiration).
+func WithNoExpiration() ItemOption {
+	return func(o *itemOptions) {
+		o.expiration = time.Time{}
+		o.slidingExpiration = 0
+	}
+}
+
+// WithSlidingExpiration is an option to set sliding expiration for any items.
+// The item expires when it has not been accessed by Get for the duration.
+// If the duration is zero or negative value, it treats as w/o sliding expiration.
+//
+// It can be combined with WithExpiration, which then limits the maximum
+// lifetime of the item regardless of accesses.
+func WithSlidingExpiration(d time.Duration) ItemOption {
+	if d <= 0 {
+		return func(o *itemOptions) {}
+	}
+	return func(o *itemOptions) {
+		o.slidingExpiration = d
+		o.defaultSliding = false
 	}
 }
 
@@ -97,17 +255,34 @@ func WithReferenceCount(referenceCount int) ItemOption {
 }
 
 // newItem creates a new item with specified any options.
-func newItem[K comparable, V any](key K, val V, opts ...ItemOption) *Item[K, V] {
-	o := new(itemOptions)
+func newItem[K comparable, V any](clock Clock, key K, val V, opts ...ItemOption) *Item[K, V] {
+	o := &itemOptions{now: clock.Now()}
 	for _, optFunc := range opts {
 		optFunc(o)
 	}
-	return &Item[K, V]{
+	if o.slidingExpiration > 0 && !o.defaultSliding && o.defaultExpiration {
+		// the sliding expiration of the item takes precedence over
+		// the default expiration of the cache.
+		o.expiration = time.Time{}
+	}
+	item := &Item[K, V]{
 		Key:                   key,
 		Value:                 val,
 		Expiration:            o.expiration,
 		InitialReferenceCount: o.referenceCount,
+		Cost:                  1,
+		StaleWhileRevalidate:  o.staleWhileRevalidate,
+		StaleIfError:          o.staleIfError,
+		clock:                 clock,
 	}
+	if o.slidingExpiration > 0 {
+		item.SlidingExpiration = o.slidingExpiration
+		item.MaxExpiration = o.expiration
+		item.Expiration = slidingExpiration(o.now, o.slidingExpiration, o.expiration)
+	} else if item.hasExpiration() {
+		item.ttl = o.expiration.Sub(o.now)
+	}
+	return item
 }
 
 // Cache is a thread safe cache.
@@ -116,21 +291,58 @@ type Cache[K comparable, V any] struct {
 	// mu is used to do lock in some method process.
 	mu         sync.Mutex
 	janitor    *janitor
-	expManager *expirationManager[K]
+	clock      Clock
+	expManager expirationTracker[K]
+	// ctx is the context given to NewContext.
+	ctx context.Context
+	// loads is used to deduplicate concurrent loads in GetOrLoad.
+	loads group[K, V]
+
+	loader         LoaderFunc[K, V]
+	refreshAhead   float64
+	onRefreshError func(key K, err error)
+	// refreshing holds keys which are being reloaded by refresh-ahead.
+	refreshing map[K]struct{}
+
+	onEvicted      func(key K, val V, reason EvictionReason)
+	evictedEntries []evictedEntry[K, V]
+
+	// stats is nil if the statistics are disabled.
+	stats *statsCounter
+
+	// closed is true after Close has been called.
+	closed bool
+
+	weigher func(key K, val V) int64
+	// itemOpts are applied to every item before the options given to Set.
+	itemOpts []ItemOption
 }
 
+// LoaderFunc is a function to load a value for the key on cache miss.
+type LoaderFunc[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
 // Option is an option for cache.
 type Option[K comparable, V any] func(*options[K, V])
 
 type options[K comparable, V any] struct {
 	cache           Interface[K, *Item[K, V]]
 	janitorInterval time.Duration
+	clock           Clock
+	wheelTick       time.Duration
+	onEvicted       func(key K, val V, reason EvictionReason)
+	stats           bool
+	weigher         func(key K, val V) int64
+	itemOpts        []ItemOption
+	loader          LoaderFunc[K, V]
+	refreshAhead    float64
+	onRefreshError  func(key K, err error)
 }
 
 func newOptions[K comparable, V any]() *options[K, V] {
 	return &options[K, V]{
 		cache:           simple.NewCache[K, *Item[K, V]](),
 		janitorInterval: time.Minute,
+		clock:           systemClock{},
 	}
 }
 
@@ -169,6 +381,34 @@ func AsClock[K comparable, V any](opts ...clock.Option) Option[K, V] {
 	}
 }
 
+// AsARC is an option to make a new Cache as ARC algorithm.
+func AsARC[K comparable, V any](opts ...arc.Option) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.cache = arc.NewCache[K, *Item[K, V]](opts...)
+	}
+}
+
+// AsTinyLFU is an option to make a new Cache as W-TinyLFU algorithm.
+func AsTinyLFU[K comparable, V any](opts ...tinylfu.Option) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.cache = tinylfu.NewCache[K, *Item[K, V]](opts...)
+	}
+}
+
+// AsSLRU is an option to make a new Cache as SLRU algorithm.
+func AsSLRU[K comparable, V any](opts ...slru.Option) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.cache = slru.NewCache[K, *Item[K, V]](opts...)
+	}
+}
+
+// As2Q is an option to make a new Cache as 2Q algorithm.
+func As2Q[K comparable, V any](opts ...twoq.Option) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.cache = twoq.NewCache[K, *Item[K, V]](opts...)
+	}
+}
+
 // WithJanitorInterval is an option to specify how often cache should delete expired items.
 //
 // Default is 1 minute.
@@ -178,10 +418,112 @@ func WithJanitorInterval[K comparable, V any](d time.Duration) Option[K, V] {
 	}
 }
 
+// WithClock is an option to set the clock which the cache uses to calculate
+// expiration times of items and to run the janitor. It is useful to control
+// time in tests (see cachetest.FakeClock).
+//
+// Default is the system clock.
+func WithClock[K comparable, V any](clock Clock) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.clock = clock
+	}
+}
+
+// WithTimingWheel is an option to track expiration times of items by a
+// hierarchical timing wheel whose resolution is tick, instead of a binary heap.
+//
+// The timing wheel schedules and cancels expiration in O(1), which is suitable
+// for a large number of items with expiration. The janitor runs every tick
+// instead of the interval specified by WithJanitorInterval, so that expired
+// items are deleted within about a tick after their expiration time.
+//
+// If the tick is zero or negative value, the binary heap is used.
+func WithTimingWheel[K comparable, V any](tick time.Duration) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.wheelTick = tick
+	}
+}
+
+// OnEvicted is an option to set a callback which is called when an entry is
+// removed from the cache, with the reason of the removal.
+//
+// The callback is called after the cache lock is released, so it is safe
+// to call methods of the cache in the callback.
+func OnEvicted[K comparable, V any](f func(key K, val V, reason EvictionReason)) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.onEvicted = f
+	}
+}
+
+// WithStats is an option to enable counting statistics of the cache.
+// The statistics can be retrieved by Stats method.
+//
+// Default is disabled.
+func WithStats[K comparable, V any]() Option[K, V] {
+	return func(o *options[K, V]) {
+		o.stats = true
+	}
+}
+
+// WithWeigher is an option to set a function which calculates the cost of
+// each entry. The cost is used by the cache policies which limit the total
+// cost instead of the number of entries, e.g. AsLRU(lru.WithMaxCost(n)).
+// LRU, LFU, FIFO, MRU, Clock, ARC and W-TinyLFU policies support the max
+// cost by their WithMaxCost option. The cost is ignored by the others.
+// An entry whose cost exceeds the max cost by itself is never stored, and
+// it is reported to OnEvicted callback with EvictionReasonCapacity.
+//
+// Default cost of each entry is 1.
+func WithWeigher[K comparable, V any](f func(key K, val V) int64) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.weigher = f
+	}
+}
+
+// WithDefaultExpiration is an option to set the expiration for all items
+// which are set by Set or GetOrSet without WithExpiration option.
+// WithNoExpiration option can be used to opt out of it for each item.
+// If the expiration is zero or negative value, it treats as w/o expiration.
+//
+// The items which are set with WithSlidingExpiration option do not have the
+// default expiration, so they expire only by the sliding expiration unless
+// WithExpiration option is also given. The default expiration combined with
+// WithDefaultSlidingExpiration limits the maximum lifetime of the items.
+//
+// Default is no expiration.
+func WithDefaultExpiration[K comparable, V any](d time.Duration) Option[K, V] {
+	return func(o *options[K, V]) {
+		if d <= 0 {
+			return
+		}
+		o.itemOpts = append(o.itemOpts, func(o *itemOptions) {
+			o.expiration = o.now.Add(d)
+			o.defaultExpiration = true
+		})
+	}
+}
+
+// WithDefaultSlidingExpiration is an option to set the sliding expiration
+// for all items in the cache. See WithSlidingExpiration for details.
+//
+// The sliding expiration given to Set or GetOrSet as an ItemOption
+// takes precedence over this.
+func WithDefaultSlidingExpiration[K comparable, V any](d time.Duration) Option[K, V] {
+	return func(o *options[K, V]) {
+		if d <= 0 {
+			return
+		}
+		o.itemOpts = append(o.itemOpts, func(o *itemOptions) {
+			o.slidingExpiration = d
+			o.defaultSliding = true
+		})
+	}
+}
+
 // New creates a new thread safe Cache.
-// The janitor will not be stopped which is created by this function. If you
-// want to stop the janitor gracefully, You should use the `NewContext` function
-// instead of this.
+// The janitor which is created by this function is stopped by Close method.
+// If you want to stop the janitor by context, You should use the `NewContext`
+// function instead of this.
 //
 // There are several Cache replacement policies available with you specified any options.
 func New[K comparable, V any](opts ...Option[K, V]) *Cache[K, V] {
@@ -197,10 +539,35 @@ func NewContext[K comparable, V any](ctx context.Context, opts ...Option[K, V])
 	for _, optFunc := range opts {
 		optFunc(o)
 	}
+	var expManager expirationTracker[K] = newExpirationManager[K]()
+	if o.wheelTick > 0 {
+		expManager = newTimingWheel[K](o.wheelTick, o.clock.Now())
+		o.janitorInterval = o.wheelTick
+	}
 	cache := &Cache[K, V]{
-		cache:      o.cache,
-		janitor:    newJanitor(ctx, o.janitorInterval),
-		expManager: newExpirationManager[K](),
+		cache:          o.cache,
+		janitor:        newJanitor(ctx, o.janitorInterval, o.clock),
+		clock:          o.clock,
+		expManager:     expManager,
+		ctx:            ctx,
+		loader:         o.loader,
+		refreshAhead:   o.refreshAhead,
+		onRefreshError: o.onRefreshError,
+		onEvicted:      o.onEvicted,
+		weigher:        o.weigher,
+		itemOpts:       o.itemOpts,
+	}
+	if o.stats {
+		cache.stats = new(statsCounter)
+	}
+	if n, ok := o.cache.(evictionNotifier[K, *Item[K, V]]); ok {
+		n.SetOnEvicted(func(key K, item *Item[K, V]) {
+			cache.expManager.remove(key)
+			cache.evicted(key, item, EvictionReasonCapacity)
+		})
+	}
+	if n, ok := o.cache.(nowFuncSetter); ok {
+		n.SetNowFunc(o.clock.Now)
 	}
 	cache.janitor.run(cache.DeleteExpired)
 	return cache
@@ -210,90 +577,250 @@ func NewContext[K comparable, V any](ctx context.Context, opts ...Option[K, V])
 func (c *Cache[K, V]) Get(key K) (zero V, ok bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	return c.get(key)
+}
+
+// get looks up a key's value from the cache. c.mu must be held.
+func (c *Cache[K, V]) get(key K) (zero V, ok bool) {
 	item, ok := c.cache.Get(key)
 
 	if !ok {
+		c.stats.lookup(false)
 		return
 	}
 
 	// Returns nil if the item has been expired.
 	// Do not delete here and leave it to an external process such as Janitor.
 	if item.Expired() {
+		c.stats.lookup(false)
 		return zero, false
 	}
 
+	c.touch(key, item)
+	c.refreshAheadIfNeeded(key, item)
+	c.stats.lookup(true)
+	return item.Value, true
+}
+
+// Peek looks up a key's value from the cache without affecting the eviction
+// order of the cache policy. Unlike Get, it neither pushes forward the sliding
+// expiration nor triggers refresh-ahead, and it is not counted in the
+// statistics.
+func (c *Cache[K, V]) Peek(key K) (zero V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	item, ok := c.cache.Peek(key)
+	if !ok || item.Expired() {
+		return zero, false
+	}
 	return item.Value, true
 }
 
+// touch pushes forward the expiration of the item accessed if the item has
+// the sliding expiration.
+func (c *Cache[K, V]) touch(key K, item *Item[K, V]) {
+	if item.touch() {
+		c.expManager.update(key, item.staleUntil())
+	}
+}
+
 // GetOrSet atomically gets a key's value from the cache, or if the
 // key is not present, sets the given value.
 // The loaded result is true if the value was loaded, false if stored.
+//
+// If the cache has been closed, it returns the given value and false
+// without storing the value.
 func (c *Cache[K, V]) GetOrSet(key K, val V, opts ...ItemOption) (actual V, loaded bool) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.unlock()
+	if c.closed {
+		return val, false
+	}
 	item, ok := c.cache.Get(key)
 
 	if !ok || item.Expired() {
-		item := newItem(key, val, opts...)
+		if ok {
+			c.evicted(key, item, EvictionReasonExpired)
+		}
+		c.stats.lookup(false)
+		item := c.newItem(key, val, opts...)
+		c.trackExpiration(key, item)
 		c.cache.Set(key, item)
 		return val, false
 	}
 
+	c.touch(key, item)
+	c.stats.lookup(true)
 	return item.Value, true
 }
 
+// GetOrLoad gets a key's value from the cache, or if the key is not present
+// (or has expired), calls loader to load the value and sets it to the cache
+// with the given options.
+//
+// The loader is called at most once at a time for the same key. Concurrent
+// callers for the key wait for the in-flight load and share its result,
+// including the error. Each caller, including the one which has started the
+// load, gives up and returns ctx.Err() if its ctx is done before the load
+// completes. The load is not cancelled by any caller giving up, since the
+// loader is called with the context given to NewContext instead of ctx.
+// The loader is called without holding the cache lock, and values are not
+// cached when the loader returns an error.
+//
+// If loader is nil, the loader registered by WithLoader is used. If no loader
+// is registered either, it returns ErrNoLoader.
+//
+// The expired value of the item which has been set with
+// WithStaleWhileRevalidate or WithStaleIfError may be returned.
+// Use GetOrLoadStale to know whether the value is stale.
+//
+// If the cache has been closed, it returns ErrClosed.
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loader LoaderFunc[K, V], opts ...ItemOption) (V, error) {
+	val, _, err := c.GetOrLoadStale(ctx, key, loader, opts...)
+	return val, err
+}
+
+// load calls loader and sets the loaded value to the cache.
+func (c *Cache[K, V]) load(ctx context.Context, key K, loader LoaderFunc[K, V], opts []ItemOption) (V, error) {
+	return c.loads.do(ctx, key, func() (V, error) {
+		// the load is shared by the callers, so it is detached from ctx.
+		val, err := loader(c.ctx, key)
+		c.stats.load(err)
+		if err != nil {
+			return val, err
+		}
+		c.Set(key, val, opts...)
+		return val, nil
+	})
+}
+
 // DeleteExpired all expired items from the cache.
 func (c *Cache[K, V]) DeleteExpired() {
 	c.mu.Lock()
-	l := c.expManager.len()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	keys := c.expiredKeys()
 	c.mu.Unlock()
+	c.deleteExpiredKeys(keys)
+}
 
-	evict := func() bool {
-		key := c.expManager.pop()
-		// if is expired, delete it and return nil instead
-		item, ok := c.cache.Get(key)
-		if ok {
-			if item.Expired() {
-				c.cache.Delete(key)
-				return false
-			}
-			c.expManager.update(key, item.Expiration)
-		}
-		return true
+// expiredKeys unregisters and returns keys which have expired.
+// c.mu must be held.
+func (c *Cache[K, V]) expiredKeys() []K {
+	if c.expManager.len() == 0 {
+		return nil
 	}
+	return c.expManager.expired(c.clock.Now())
+}
 
-	for i := 0; i < l; i++ {
+// deleteExpiredKeys deletes the items with the keys if they have expired.
+func (c *Cache[K, V]) deleteExpiredKeys(keys []K) {
+	for _, key := range keys {
 		c.mu.Lock()
-		shouldBreak := evict()
-		c.mu.Unlock()
-		if shouldBreak {
-			break
-		}
+		c.deleteExpired(key)
+		c.unlock()
+	}
+}
+
+// deleteExpired deletes the item with the key if it has expired.
+// c.mu must be held.
+func (c *Cache[K, V]) deleteExpired(key K) {
+	item, ok := c.cache.Peek(key)
+	if !ok {
+		return
+	}
+	if item.discardable() {
+		c.cache.Delete(key)
+		c.evicted(key, item, EvictionReasonExpired)
+		return
 	}
+	// the item has been set again after the key was expired, or it is
+	// still retained to be served as a stale value.
+	c.trackExpiration(key, item)
 }
 
 // Set sets a value to the cache with key. replacing any existing value.
+//
+// It does nothing if the cache has been closed.
 func (c *Cache[K, V]) Set(key K, val V, opts ...ItemOption) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-	item := newItem(key, val, opts...)
-	if item.hasExpiration() {
-		c.expManager.update(key, item.Expiration)
+	defer c.unlock()
+	if c.closed {
+		return
 	}
+	c.replacing(key)
+	item := c.newItem(key, val, opts...)
+	c.trackExpiration(key, item)
 	c.cache.Set(key, item)
 }
 
+// replacing reports the existing item of the key which is about to be
+// replaced to the eviction callback and the statistics. c.mu must be held.
+func (c *Cache[K, V]) replacing(key K) {
+	if !c.tracksEvictions() {
+		return
+	}
+	if old, ok := c.cache.Peek(key); ok {
+		reason := EvictionReasonReplaced
+		if old.Expired() {
+			reason = EvictionReasonExpired
+		}
+		c.evicted(key, old, reason)
+	}
+}
+
+// trackExpiration registers the expiration of the item to be deleted by
+// DeleteExpired, or unregisters the key if the item never expires.
+func (c *Cache[K, V]) trackExpiration(key K, item *Item[K, V]) {
+	if item.hasExpiration() {
+		c.expManager.update(key, item.staleUntil())
+	} else {
+		c.expManager.remove(key)
+	}
+}
+
+// newItem creates a new item for the cache with specified any options.
+func (c *Cache[K, V]) newItem(key K, val V, opts ...ItemOption) *Item[K, V] {
+	if len(c.itemOpts) > 0 {
+		opts = append(append(make([]ItemOption, 0, len(c.itemOpts)+len(opts)), c.itemOpts...), opts...)
+	}
+	item := newItem(c.clock, key, val, opts...)
+	if c.weigher != nil {
+		item.Cost = c.weigher(key, val)
+	}
+	return item
+}
+
 // Keys returns the keys of the cache. the order is relied on algorithms.
 func (c *Cache[K, V]) Keys() []K {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
 	return c.cache.Keys()
 }
 
 // Delete deletes the item with provided key from the cache.
 func (c *Cache[K, V]) Delete(key K) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.unlock()
+	if c.closed {
+		return
+	}
+	if c.tracksEvictions() {
+		if item, ok := c.cache.Peek(key); ok {
+			c.evicted(key, item, EvictionReasonDeleted)
+		}
+	}
 	c.cache.Delete(key)
 	c.expManager.remove(key)
 }
@@ -302,14 +829,22 @@ func (c *Cache[K, V]) Delete(key K) {
 func (c *Cache[K, V]) Len() int {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	if c.closed {
+		return 0
+	}
 	return c.cache.Len()
 }
 
 // Contains reports whether key is within cache.
+// It does not affect the eviction order of the cache policy.
 func (c *Cache[K, V]) Contains(key K) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	_, ok := c.cache.Get(key)
+	if c.closed {
+		return false
+	}
+	_, ok := c.cache.Peek(key)
+	c.stats.lookup(ok)
 	return ok
 }
 
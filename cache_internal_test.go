@@ -2,24 +2,33 @@ package cache
 
 import (
 	"context"
+	"errors"
+	"math/rand"
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/Code-Hex/go-generics-cache/cachetest"
+	"github.com/Code-Hex/go-generics-cache/policy/arc"
+	"github.com/Code-Hex/go-generics-cache/policy/clock"
+	"github.com/Code-Hex/go-generics-cache/policy/fifo"
+	"github.com/Code-Hex/go-generics-cache/policy/lfu"
+	"github.com/Code-Hex/go-generics-cache/policy/lru"
+	"github.com/Code-Hex/go-generics-cache/policy/mru"
+	"github.com/Code-Hex/go-generics-cache/policy/slru"
+	"github.com/Code-Hex/go-generics-cache/policy/tinylfu"
+	"github.com/Code-Hex/go-generics-cache/policy/twoq"
 )
 
 func TestDeletedCache(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	restore := func() {
-		nowFunc = time.Now
-	}
-	defer restore()
 
-	nc := NewContext[string, int](ctx)
+	clk := cachetest.NewFakeClock(time.Now())
+	nc := NewContext(ctx, WithClock[string, int](clk))
 	key := "key"
 	nc.Set(key, 1, WithExpiration(1*time.Second))
-	nowFunc = func() time.Time {
-		return time.Now().Add(2 * time.Second)
-	}
+	clk.Advance(2 * time.Second)
 	_, ok := nc.cache.Get(key)
 	if !ok {
 		t.Fatal("want true")
@@ -35,13 +44,10 @@ func TestDeletedCache(t *testing.T) {
 
 func TestDeleteExpired(t *testing.T) {
 	now := time.Now()
-	restore := func() {
-		nowFunc = time.Now
-	}
 
 	t.Run("normal", func(t *testing.T) {
-		defer restore()
-		c := New[string, int]()
+		clk := cachetest.NewFakeClock(now)
+		c := New(WithClock[string, int](clk))
 
 		c.Set("0", 0)
 		c.Set("1", 10, WithExpiration(10*time.Millisecond))
@@ -55,11 +61,9 @@ func TestDeleteExpired(t *testing.T) {
 		expItems := 2
 
 		for i := 0; i <= maxItems; i++ {
-			nowFunc = func() time.Time {
-				// Advance time to expire some items
-				advanced := time.Duration(i * 10)
-				return now.Add(advanced * time.Millisecond).Add(time.Millisecond)
-			}
+			// Advance time to expire some items
+			advanced := time.Duration(i * 10)
+			advanceTo(clk, now.Add(advanced*time.Millisecond).Add(time.Millisecond))
 
 			c.DeleteExpired()
 
@@ -72,8 +76,8 @@ func TestDeleteExpired(t *testing.T) {
 	})
 
 	t.Run("with remove", func(t *testing.T) {
-		defer restore()
-		c := New[string, int]()
+		clk := cachetest.NewFakeClock(now)
+		c := New(WithClock[string, int](clk))
 
 		c.Set("0", 0)
 		c.Set("1", 10, WithExpiration(10*time.Millisecond))
@@ -81,9 +85,7 @@ func TestDeleteExpired(t *testing.T) {
 
 		c.Delete("1")
 
-		nowFunc = func() time.Time {
-			return now.Add(30 * time.Millisecond).Add(time.Millisecond)
-		}
+		advanceTo(clk, now.Add(30*time.Millisecond).Add(time.Millisecond))
 
 		c.DeleteExpired()
 
@@ -95,8 +97,8 @@ func TestDeleteExpired(t *testing.T) {
 	})
 
 	t.Run("with update", func(t *testing.T) {
-		defer restore()
-		c := New[string, int]()
+		clk := cachetest.NewFakeClock(now)
+		c := New(WithClock[string, int](clk))
 
 		c.Set("0", 0)
 		c.Set("1", 10, WithExpiration(10*time.Millisecond))
@@ -105,9 +107,7 @@ func TestDeleteExpired(t *testing.T) {
 
 		maxItems := c.Len()
 
-		nowFunc = func() time.Time {
-			return now.Add(10 * time.Millisecond).Add(time.Millisecond)
-		}
+		advanceTo(clk, now.Add(10*time.Millisecond).Add(time.Millisecond))
 
 		c.DeleteExpired()
 
@@ -117,9 +117,7 @@ func TestDeleteExpired(t *testing.T) {
 			t.Errorf("want1 %d items but got1 %d", want1, got1)
 		}
 
-		nowFunc = func() time.Time {
-			return now.Add(30 * time.Millisecond).Add(time.Millisecond)
-		}
+		advanceTo(clk, now.Add(30*time.Millisecond).Add(time.Millisecond))
 
 		c.DeleteExpired()
 
@@ -131,16 +129,14 @@ func TestDeleteExpired(t *testing.T) {
 	})
 
 	t.Run("issue #51", func(t *testing.T) {
-		defer restore()
-		c := New[string, int]()
+		clk := cachetest.NewFakeClock(now)
+		c := New(WithClock[string, int](clk))
 
 		c.Set("1", 10, WithExpiration(10*time.Millisecond))
 		c.Set("2", 20, WithExpiration(20*time.Millisecond))
 		c.Set("1", 30, WithExpiration(100*time.Millisecond)) // expected do not expired key "1"
 
-		nowFunc = func() time.Time {
-			return now.Add(30 * time.Millisecond).Add(time.Millisecond)
-		}
+		advanceTo(clk, now.Add(30*time.Millisecond).Add(time.Millisecond))
 
 		c.DeleteExpired()
 
@@ -151,8 +147,8 @@ func TestDeleteExpired(t *testing.T) {
 	})
 
 	t.Run("issue #64", func(t *testing.T) {
-		defer restore()
-		c := New[string, int]()
+		clk := cachetest.NewFakeClock(now)
+		c := New(WithClock[string, int](clk))
 		c.Set("1", 4, WithExpiration(0))  // These should not be expired
 		c.Set("2", 5, WithExpiration(-1)) // These should not be expired
 		c.Set("3", 6, WithExpiration(1*time.Hour))
@@ -181,3 +177,420 @@ func max(x, y int) int {
 	}
 	return x
 }
+
+func TestOnEvicted(t *testing.T) {
+	now := time.Now()
+	clk := cachetest.NewFakeClock(now)
+
+	type evicted struct {
+		key    string
+		val    int
+		reason EvictionReason
+	}
+	var got []evicted
+	var c *Cache[string, int]
+	c = New(
+		AsLRU[string, int](lru.WithCapacity(2)),
+		WithClock[string, int](clk),
+		OnEvicted(func(key string, val int, reason EvictionReason) {
+			// calling cache method must not be deadlocked.
+			_ = c.Len()
+			got = append(got, evicted{key: key, val: val, reason: reason})
+		}),
+	)
+
+	c.Set("a", 1)
+	c.Set("a", 2)                                      // replaced
+	c.Set("b", 3, WithExpiration(10*time.Millisecond)) // expired later
+	c.Set("c", 4)                                      // "a" is evicted by capacity
+	c.Delete("c")                                      // deleted
+	c.Delete("c")                                      // not found
+	advanceTo(clk, now.Add(time.Second))
+	c.DeleteExpired()
+
+	want := []evicted{
+		{key: "a", val: 1, reason: EvictionReasonReplaced},
+		{key: "a", val: 2, reason: EvictionReasonCapacity},
+		{key: "c", val: 4, reason: EvictionReasonDeleted},
+		{key: "b", val: 3, reason: EvictionReasonExpired},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v but got %v", want, got)
+	}
+}
+
+func TestStats(t *testing.T) {
+	now := time.Now()
+	clk := cachetest.NewFakeClock(now)
+
+	t.Run("enabled", func(t *testing.T) {
+		c := New(
+			AsLRU[string, int](lru.WithCapacity(2)),
+			WithClock[string, int](clk),
+			WithStats[string, int](),
+		)
+		c.Set("a", 1)
+		c.Set("a", 2) // replaced
+		c.Get("a")    // hit
+		c.Get("b")    // miss
+		c.Contains("a")
+		c.GetOrSet("b", 1) // miss
+		c.GetOrSet("b", 1) // hit
+		c.Set("b", 2, WithExpiration(time.Millisecond))
+		c.Set("c", 3) // "a" is evicted
+		c.Delete("c")
+		_, _ = c.GetOrLoad(context.Background(), "d", func(context.Context, string) (int, error) {
+			return 4, nil
+		})
+		_, _ = c.GetOrLoad(context.Background(), "e", func(context.Context, string) (int, error) {
+			return 0, errors.New("error")
+		})
+		advanceTo(clk, now.Add(time.Second))
+		c.DeleteExpired()
+
+		want := Stats{
+			Hits:       3,
+			Misses:     4,
+			Loads:      1,
+			LoadErrors: 1,
+			Evictions: map[EvictionReason]uint64{
+				EvictionReasonCapacity: 1,
+				EvictionReasonDeleted:  1,
+				EvictionReasonReplaced: 2,
+			},
+			Expirations: 1,
+			Size:        1,
+			HitRatio:    3.0 / 7.0,
+		}
+		if got := c.Stats(); !reflect.DeepEqual(want, got) {
+			t.Errorf("want %+v but got %+v", want, got)
+		}
+
+		c.ResetStats()
+		want = Stats{
+			Evictions: map[EvictionReason]uint64{
+				EvictionReasonCapacity: 0,
+				EvictionReasonDeleted:  0,
+				EvictionReasonReplaced: 0,
+			},
+			Size: 1,
+		}
+		if got := c.Stats(); !reflect.DeepEqual(want, got) {
+			t.Errorf("want %+v after reset but got %+v", want, got)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		c := New[string, int]()
+		c.Set("a", 1)
+		c.Get("a")
+		c.Get("b")
+
+		want := Stats{Size: 1}
+		if got := c.Stats(); !reflect.DeepEqual(want, got) {
+			t.Errorf("want %+v but got %+v", want, got)
+		}
+	})
+}
+
+func TestSlidingExpiration(t *testing.T) {
+	now := time.Now()
+
+	t.Run("extended by access", func(t *testing.T) {
+		clk := cachetest.NewFakeClock(now)
+		advance := func(d time.Duration) {
+			advanceTo(clk, now.Add(d))
+		}
+		c := New(WithClock[string, int](clk))
+		c.Set("a", 1, WithSlidingExpiration(10*time.Second))
+		c.Set("b", 2, WithSlidingExpiration(10*time.Second))
+
+		advance(5 * time.Second)
+		if _, ok := c.Get("a"); !ok {
+			t.Fatal("want a is found")
+		}
+
+		advance(14 * time.Second)
+		c.DeleteExpired()
+		if _, ok := c.Get("a"); !ok {
+			t.Fatal("want a is found after extended")
+		}
+		if c.Contains("b") {
+			t.Fatal("want b is expired")
+		}
+		if got := c.Len(); got != 1 {
+			t.Fatalf("want length 1 but got %d", got)
+		}
+
+		advance(25 * time.Second)
+		c.DeleteExpired()
+		if got := c.Len(); got != 0 {
+			t.Fatalf("want length 0 but got %d", got)
+		}
+	})
+
+	t.Run("with max lifetime", func(t *testing.T) {
+		clk := cachetest.NewFakeClock(now)
+		advance := func(d time.Duration) {
+			advanceTo(clk, now.Add(d))
+		}
+		c := New(WithClock[string, int](clk))
+		c.Set("a", 1,
+			WithSlidingExpiration(10*time.Second),
+			WithExpiration(15*time.Second),
+		)
+		for _, d := range []time.Duration{5 * time.Second, 10 * time.Second, 14 * time.Second} {
+			advance(d)
+			if _, ok := c.Get("a"); !ok {
+				t.Fatalf("want a is found at %v", d)
+			}
+		}
+		advance(16 * time.Second)
+		if _, ok := c.Get("a"); ok {
+			t.Fatal("want a is expired by the max lifetime")
+		}
+	})
+
+	t.Run("default", func(t *testing.T) {
+		clk := cachetest.NewFakeClock(now)
+		c := New(
+			WithClock[string, int](clk),
+			WithDefaultSlidingExpiration[string, int](10*time.Second),
+		)
+		c.Set("a", 1)
+		c.Set("b", 2, WithSlidingExpiration(time.Minute))
+
+		clk.Advance(11 * time.Second)
+		if _, ok := c.Get("a"); ok {
+			t.Fatal("want a is expired")
+		}
+		if _, ok := c.Get("b"); !ok {
+			t.Fatal("want b is found")
+		}
+	})
+}
+
+func TestDefaultExpiration(t *testing.T) {
+	now := time.Now()
+	clk := cachetest.NewFakeClock(now)
+
+	c := New(
+		WithClock[string, int](clk),
+		WithDefaultExpiration[string, int](10*time.Second),
+	)
+	c.Set("a", 1)
+	c.Set("b", 2, WithExpiration(time.Minute))
+	c.Set("c", 3, WithNoExpiration())
+	c.GetOrSet("d", 4)
+	c.GetOrSet("e", 5, WithNoExpiration())
+	c.Set("f", 6)
+	c.Set("f", 7, WithNoExpiration()) // no longer expires
+
+	if got, want := c.expManager.len(), 3; got != want {
+		t.Fatalf("want %d expiring items but got %d", want, got)
+	}
+
+	advanceTo(clk, now.Add(11*time.Second))
+	c.DeleteExpired()
+
+	want := []string{"b", "c", "e", "f"}
+	if got := c.Keys(); !reflect.DeepEqual(want, got) {
+		t.Errorf("want keys %v but got %v", want, got)
+	}
+}
+
+func TestDefaultExpirationWithSlidingExpiration(t *testing.T) {
+	now := time.Now()
+	clk := cachetest.NewFakeClock(now)
+
+	c := New(
+		WithClock[string, int](clk),
+		WithDefaultExpiration[string, int](time.Minute),
+	)
+	// the sliding expiration of the item takes precedence over the default.
+	c.Set("a", 1, WithSlidingExpiration(time.Hour))
+	// the expiration given to the item limits the lifetime.
+	c.Set("b", 2, WithSlidingExpiration(time.Hour), WithExpiration(90*time.Second))
+
+	for i := 1; i <= 3; i++ {
+		advanceTo(clk, now.Add(time.Duration(i)*time.Minute))
+		c.DeleteExpired()
+		if _, ok := c.Get("a"); !ok {
+			t.Fatalf("want a is alive after %d minutes", i)
+		}
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("want b is expired by its max lifetime")
+	}
+
+	// both defaults are combined.
+	d := New(
+		WithClock[string, int](clk),
+		WithDefaultExpiration[string, int](time.Minute),
+		WithDefaultSlidingExpiration[string, int](time.Hour),
+	)
+	d.Set("a", 1)
+	advanceTo(clk, now.Add(5*time.Minute))
+	if _, ok := d.Get("a"); ok {
+		t.Errorf("want a is expired by the default expiration")
+	}
+}
+
+func TestExpirationManagerConsistency(t *testing.T) {
+	now := time.Now()
+
+	cases := []struct {
+		name string
+		opts []Option[int, int]
+	}{
+		{
+			name: "LRU",
+			opts: []Option[int, int]{AsLRU[int, int](lru.WithCapacity(10))},
+		},
+		{
+			name: "LRU with max cost",
+			opts: []Option[int, int]{
+				AsLRU[int, int](lru.WithMaxCost(20)),
+				WithWeigher(func(_ int, val int) int64 { return int64(val % 8) }),
+			},
+		},
+		{
+			name: "LFU",
+			opts: []Option[int, int]{AsLFU[int, int](lfu.WithCapacity(10))},
+		},
+		{
+			name: "FIFO",
+			opts: []Option[int, int]{AsFIFO[int, int](fifo.WithCapacity(10))},
+		},
+		{
+			name: "MRU",
+			opts: []Option[int, int]{AsMRU[int, int](mru.WithCapacity(10))},
+		},
+		{
+			name: "Clock",
+			opts: []Option[int, int]{AsClock[int, int](clock.WithCapacity(10))},
+		},
+		{
+			name: "ARC",
+			opts: []Option[int, int]{AsARC[int, int](arc.WithCapacity(10))},
+		},
+		{
+			name: "TinyLFU",
+			opts: []Option[int, int]{AsTinyLFU[int, int](tinylfu.WithCapacity(10))},
+		},
+		{
+			name: "ARC with max cost",
+			opts: []Option[int, int]{
+				AsARC[int, int](arc.WithMaxCost(20)),
+				WithWeigher(func(_ int, val int) int64 { return int64(val % 8) }),
+			},
+		},
+		{
+			name: "TinyLFU with max cost",
+			opts: []Option[int, int]{
+				AsTinyLFU[int, int](tinylfu.WithMaxCost(20)),
+				WithWeigher(func(_ int, val int) int64 { return int64(val % 8) }),
+			},
+		},
+		{
+			name: "SLRU",
+			opts: []Option[int, int]{AsSLRU[int, int](slru.WithCapacity(10))},
+		},
+		{
+			name: "2Q",
+			opts: []Option[int, int]{As2Q[int, int](twoq.WithCapacity(10))},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			clk := cachetest.NewFakeClock(now)
+			c := New(append(tc.opts, WithClock[int, int](clk))...)
+			rnd := rand.New(rand.NewSource(1))
+			for i := 0; i < 10000; i++ {
+				key := rnd.Intn(50)
+				var opts []ItemOption
+				if rnd.Intn(2) == 0 {
+					opts = append(opts, WithExpiration(time.Duration(rnd.Intn(100)+1)*time.Millisecond))
+				}
+				switch rnd.Intn(10) {
+				case 0:
+					c.Delete(key)
+				case 1:
+					c.GetOrSet(key, i, opts...)
+				case 2:
+					c.Get(key)
+				case 3:
+					elapsed := time.Duration(i) * time.Millisecond
+					advanceTo(clk, now.Add(elapsed))
+					c.DeleteExpired()
+				case 4:
+					c.SetMany(map[int]int{key: i, (key + 1) % 50: i, (key + 7) % 50: i}, opts...)
+				case 5:
+					c.DeleteMany([]int{key, (key + 3) % 50})
+				case 6:
+					c.Resize(5 + rnd.Intn(10))
+				case 7:
+					if rnd.Intn(10) == 0 {
+						c.Clear()
+					}
+				default:
+					c.Set(key, i, opts...)
+				}
+
+				c.mu.Lock()
+				want := 0
+				for _, key := range c.cache.Keys() {
+					if item, ok := c.cache.Peek(key); ok && item.hasExpiration() {
+						want++
+					}
+				}
+				got := c.expManager.len()
+				c.mu.Unlock()
+				if got != want {
+					t.Fatalf("step %d: want %d expiring items but got %d", i, want, got)
+				}
+			}
+		})
+	}
+}
+
+// advanceTo advances the clock to t.
+func advanceTo(clk *cachetest.FakeClock, t time.Time) {
+	clk.Advance(t.Sub(clk.Now()))
+}
+
+func TestGroupPanic(t *testing.T) {
+	var g group[string, int]
+	release := make(chan struct{})
+	started := make(chan struct{})
+	waiterErr := make(chan error, 1)
+	go func() {
+		<-started
+		_, err := g.do(context.Background(), "a", func() (int, error) {
+			t.Error("fn should not be called while another call is in-flight")
+			return 0, nil
+		})
+		waiterErr <- err
+	}()
+
+	defer func() {
+		if got := recover(); got != "boom" {
+			t.Errorf("want the panic is propagated to the first caller, but got %v", got)
+		}
+		if err := <-waiterErr; !errors.Is(err, errLoaderPanicked) {
+			t.Errorf("want %v but got %v", errLoaderPanicked, err)
+		}
+	}()
+	go func() {
+		<-started
+		time.Sleep(10 * time.Millisecond) // waiting for the waiter to join the call
+		close(release)
+	}()
+	_, _ = g.do(context.Background(), "a", func() (int, error) {
+		close(started)
+		<-release
+		panic("boom")
+	})
+	t.Fatal("want panic")
+}
@@ -1,17 +1,24 @@
 package cache_test
 
 import (
+	"context"
+	"errors"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	cache "github.com/Code-Hex/go-generics-cache"
+	"github.com/Code-Hex/go-generics-cache/policy/arc"
 	"github.com/Code-Hex/go-generics-cache/policy/clock"
 	"github.com/Code-Hex/go-generics-cache/policy/fifo"
 	"github.com/Code-Hex/go-generics-cache/policy/lfu"
 	"github.com/Code-Hex/go-generics-cache/policy/lru"
 	"github.com/Code-Hex/go-generics-cache/policy/mru"
+	"github.com/Code-Hex/go-generics-cache/policy/slru"
+	"github.com/Code-Hex/go-generics-cache/policy/tinylfu"
+	"github.com/Code-Hex/go-generics-cache/policy/twoq"
 )
 
 func TestMultiThreadIncr(t *testing.T) {
@@ -81,6 +88,22 @@ func TestMultiThread(t *testing.T) {
 			name:   "LFU",
 			policy: cache.AsLFU[int, int](lfu.WithCapacity(10)),
 		},
+		{
+			name:   "ARC",
+			policy: cache.AsARC[int, int](arc.WithCapacity(10)),
+		},
+		{
+			name:   "TinyLFU",
+			policy: cache.AsTinyLFU[int, int](tinylfu.WithCapacity(10)),
+		},
+		{
+			name:   "SLRU",
+			policy: cache.AsSLRU[int, int](slru.WithCapacity(10)),
+		},
+		{
+			name:   "2Q",
+			policy: cache.As2Q[int, int](twoq.WithCapacity(10)),
+		},
 	}
 	for _, tc := range cases {
 		tc := tc
@@ -121,3 +144,330 @@ func TestCallJanitor(t *testing.T) {
 		t.Errorf("want items is empty but got %d", len(keys))
 	}
 }
+
+func TestGetOrLoad(t *testing.T) {
+	t.Run("deduplicate concurrent loads", func(t *testing.T) {
+		c := cache.New[string, int]()
+
+		var calls int64
+		release := make(chan struct{})
+		loader := func(ctx context.Context, key string) (int, error) {
+			atomic.AddInt64(&calls, 1)
+			<-release
+			return 10, nil
+		}
+
+		var wg sync.WaitGroup
+		results := make([]int, 10)
+		for i := 0; i < len(results); i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				v, err := c.GetOrLoad(context.Background(), "a", loader)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				results[i] = v
+			}(i)
+		}
+		time.Sleep(50 * time.Millisecond) // waiting for all callers to join the load
+		close(release)
+		wg.Wait()
+
+		if got := atomic.LoadInt64(&calls); got != 1 {
+			t.Errorf("want loader to be called once but got %d", got)
+		}
+		for i, v := range results {
+			if v != 10 {
+				t.Errorf("results[%d]: want %d but got %d", i, 10, v)
+			}
+		}
+		if v, ok := c.Get("a"); v != 10 || !ok {
+			t.Errorf("want loaded value is cached but got %d, %v", v, ok)
+		}
+	})
+
+	t.Run("cached value", func(t *testing.T) {
+		c := cache.New[string, int]()
+		c.Set("a", 1)
+		v, err := c.GetOrLoad(context.Background(), "a", func(ctx context.Context, key string) (int, error) {
+			t.Fatal("loader should not be called")
+			return 0, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != 1 {
+			t.Errorf("want %d but got %d", 1, v)
+		}
+	})
+
+	t.Run("error is not cached", func(t *testing.T) {
+		c := cache.New[string, int]()
+		wantErr := errors.New("failed")
+		_, err := c.GetOrLoad(context.Background(), "a", func(ctx context.Context, key string) (int, error) {
+			return 0, wantErr
+		})
+		if !errors.Is(err, wantErr) {
+			t.Errorf("want %v but got %v", wantErr, err)
+		}
+		if c.Contains("a") {
+			t.Errorf("want failed load is not cached")
+		}
+	})
+
+	t.Run("waiter context is cancelled", func(t *testing.T) {
+		c := cache.New[string, int]()
+		release := make(chan struct{})
+		defer close(release)
+		started := make(chan struct{})
+		go c.GetOrLoad(context.Background(), "a", func(ctx context.Context, key string) (int, error) {
+			close(started)
+			<-release
+			return 1, nil
+		})
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := c.GetOrLoad(ctx, "a", func(ctx context.Context, key string) (int, error) {
+			t.Fatal("loader should not be called while another load is in-flight")
+			return 0, nil
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("want %v but got %v", context.DeadlineExceeded, err)
+		}
+	})
+
+	t.Run("first caller context is cancelled", func(t *testing.T) {
+		c := cache.New[string, int]()
+		release := make(chan struct{})
+		started := make(chan struct{})
+		var loaderErr error
+		loader := func(ctx context.Context, key string) (int, error) {
+			close(started)
+			<-release
+			loaderErr = ctx.Err()
+			return 1, nil
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		firstErr := make(chan error, 1)
+		go func() {
+			_, err := c.GetOrLoad(ctx, "a", loader)
+			firstErr <- err
+		}()
+		<-started
+
+		waiterResult := make(chan int, 1)
+		go func() {
+			v, err := c.GetOrLoad(context.Background(), "a", loader)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			waiterResult <- v
+		}()
+		time.Sleep(10 * time.Millisecond) // waiting for the waiter to join the load
+
+		// the first caller gives up without cancelling the shared load.
+		cancel()
+		if err := <-firstErr; !errors.Is(err, context.Canceled) {
+			t.Errorf("want %v but got %v", context.Canceled, err)
+		}
+		close(release)
+		if v := <-waiterResult; v != 1 {
+			t.Errorf("want %d but got %d", 1, v)
+		}
+		if loaderErr != nil {
+			t.Errorf("want the loader context is not cancelled but got %v", loaderErr)
+		}
+		if v, ok := c.Get("a"); v != 1 || !ok {
+			t.Errorf("want loaded value is cached but got %d, %v", v, ok)
+		}
+	})
+}
+
+func TestWeigher(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy cache.Option[string, string]
+	}{
+		{
+			name:   "LRU",
+			policy: cache.AsLRU[string, string](lru.WithMaxCost(10)),
+		},
+		{
+			name:   "LFU",
+			policy: cache.AsLFU[string, string](lfu.WithMaxCost(10)),
+		},
+		{
+			name:   "FIFO",
+			policy: cache.AsFIFO[string, string](fifo.WithMaxCost(10)),
+		},
+		{
+			name:   "MRU",
+			policy: cache.AsMRU[string, string](mru.WithMaxCost(10)),
+		},
+		{
+			name:   "Clock",
+			policy: cache.AsClock[string, string](clock.WithMaxCost(10)),
+		},
+		{
+			name:   "ARC",
+			policy: cache.AsARC[string, string](arc.WithMaxCost(10)),
+		},
+		{
+			name:   "TinyLFU",
+			policy: cache.AsTinyLFU[string, string](tinylfu.WithMaxCost(10)),
+		},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var rejected []string
+			c := cache.New(
+				tc.policy,
+				cache.WithWeigher(func(_ string, val string) int64 {
+					return int64(len(val))
+				}),
+				cache.OnEvicted(func(key string, _ string, reason cache.EvictionReason) {
+					if reason == cache.EvictionReasonCapacity {
+						rejected = append(rejected, key)
+					}
+				}),
+			)
+			c.Set("a", "aaaa")
+			c.Set("b", "bbbb")
+			c.Set("c", "cccc") // over the max cost
+			if got := c.Len(); got != 2 {
+				t.Fatalf("want length 2, but got %d", got)
+			}
+			if got := len(rejected); got != 1 {
+				t.Fatalf("want 1 eviction, but got %d", got)
+			}
+
+			c.Set("d", "ddddddddddd") // exceeds the max cost by itself
+			if c.Contains("d") {
+				t.Fatalf("want d is not stored")
+			}
+			if got := c.Len(); got != 2 {
+				t.Fatalf("want length 2, but got %d", got)
+			}
+			if got := rejected[len(rejected)-1]; got != "d" {
+				t.Fatalf("want d is reported, but got %q", got)
+			}
+		})
+	}
+}
+
+func TestPeek(t *testing.T) {
+	c := cache.New(cache.AsLRU[string, int](lru.WithCapacity(2)), cache.WithStats[string, int]())
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3, cache.WithExpiration(time.Nanosecond))
+	c.Set("b", 2)
+	time.Sleep(time.Millisecond)
+
+	if got, ok := c.Peek("a"); ok {
+		t.Fatalf("want a is evicted but got %d", got)
+	}
+	if got, ok := c.Peek("c"); ok {
+		t.Fatalf("want c has expired but got %d", got)
+	}
+	c.Set("a", 1)
+	if got, ok := c.Peek("b"); got != 2 || !ok {
+		t.Fatalf("want 2 but got %d, %v", got, ok)
+	}
+	if !c.Contains("b") {
+		t.Fatal("want b is contained")
+	}
+
+	// "b" is still the least recently used.
+	c.Set("d", 4)
+	if c.Contains("b") {
+		t.Fatal("want b is evicted")
+	}
+	if got := c.Stats().Hits; got != 1 {
+		t.Errorf("want only Contains is counted but got %d hits", got)
+	}
+}
+
+func TestResize(t *testing.T) {
+	var evicted []string
+	c := cache.New(
+		cache.AsLRU[string, int](lru.WithCapacity(3)),
+		cache.OnEvicted(func(key string, _ int, reason cache.EvictionReason) {
+			evicted = append(evicted, key+":"+reason.String())
+		}),
+	)
+	c.Set("a", 1)
+	c.Set("b", 2, cache.WithExpiration(time.Hour))
+	c.Set("c", 3)
+	if got := c.Resize(1); got != 2 {
+		t.Fatalf("want 2 evicted items but got %d", got)
+	}
+	if want := []string{"a:capacity", "b:capacity"}; len(evicted) != 2 || evicted[0] != want[0] || evicted[1] != want[1] {
+		t.Errorf("want evicted %v but got %v", want, evicted)
+	}
+	c.Resize(2)
+	c.Set("d", 4)
+	if got := c.Len(); got != 2 {
+		t.Errorf("want 2 items but got %d", got)
+	}
+
+	// less than 1 is treated as 1.
+	fifoCache := cache.New(cache.AsFIFO[string, int]())
+	fifoCache.Set("a", 1)
+	fifoCache.Set("b", 2)
+	if got := fifoCache.Resize(0); got != 1 {
+		t.Errorf("want 1 evicted item but got %d", got)
+	}
+	fifoCache.Set("c", 3)
+	if got, ok := fifoCache.Get("c"); got != 3 || !ok || fifoCache.Len() != 1 {
+		t.Errorf("want only c is stored but got keys %v", fifoCache.Keys())
+	}
+
+	// not supported.
+	arcCache := cache.New(cache.AsARC[string, int](arc.WithCapacity(1)))
+	arcCache.Set("a", 1)
+	if got := arcCache.Resize(0); got != 0 {
+		t.Errorf("want 0 evicted items but got %d", got)
+	}
+}
+
+func TestClear(t *testing.T) {
+	for _, withWheel := range []bool{false, true} {
+		var evicted []string
+		opts := []cache.Option[string, int]{
+			cache.AsLRU[string, int](),
+			cache.OnEvicted(func(key string, _ int, reason cache.EvictionReason) {
+				evicted = append(evicted, key+":"+reason.String())
+			}),
+		}
+		if withWheel {
+			opts = append(opts, cache.WithTimingWheel[string, int](time.Millisecond))
+		}
+		c := cache.New(opts...)
+		c.Set("a", 1, cache.WithExpiration(time.Hour))
+		c.Set("b", 2)
+		c.Clear()
+		if got := c.Len(); got != 0 {
+			t.Fatalf("want no items but got %d", got)
+		}
+		if len(evicted) != 0 {
+			t.Fatalf("want no evictions but got %v", evicted)
+		}
+
+		c.Set("c", 3, cache.WithExpiration(time.Hour))
+		c.Set("d", 4)
+		c.Purge()
+		if got := c.Len(); got != 0 {
+			t.Fatalf("want no items but got %d", got)
+		}
+		if want := []string{"c:deleted", "d:deleted"}; len(evicted) != 2 || evicted[0] != want[0] || evicted[1] != want[1] {
+			t.Errorf("want evicted %v but got %v", want, evicted)
+		}
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Package cachetest provides utilities for testing code which uses the cache.
+package cachetest
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// FakeClock is a clock which is advanced manually. It implements cache.Clock.
+//
+// Functions scheduled by AfterFunc are called by Advance synchronously, so
+// the janitor of the cache using the clock runs deterministically.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	seq    int
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	when time.Time
+	seq  int // to keep the order of timers which have the same time.
+	f    func()
+}
+
+// NewFakeClock creates a new FakeClock whose current time is now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc schedules f to be called by Advance when the clock is advanced
+// by the duration. It returns a function which cancels the call.
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) (stop func() bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	t := &fakeTimer{
+		when: c.now.Add(d),
+		seq:  c.seq,
+		f:    f,
+	}
+	c.timers = append(c.timers, t)
+	return func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for i, timer := range c.timers {
+			if timer == t {
+				c.timers = append(c.timers[:i], c.timers[i+1:]...)
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Advance advances the clock by the duration. Functions scheduled by AfterFunc
+// are called in order of their time, with the clock set to the time, until
+// the time of the clock reaches the end.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	c.mu.Unlock()
+	for {
+		c.mu.Lock()
+		t := c.next(end)
+		if t == nil {
+			c.now = end
+			c.mu.Unlock()
+			return
+		}
+		c.now = t.when
+		c.mu.Unlock()
+		t.f()
+	}
+}
+
+// next removes and returns the earliest timer until end. c.mu must be held.
+func (c *FakeClock) next(end time.Time) *fakeTimer {
+	if len(c.timers) == 0 {
+		return nil
+	}
+	sort.Slice(c.timers, func(i, j int) bool {
+		if c.timers[i].when.Equal(c.timers[j].when) {
+			return c.timers[i].seq < c.timers[j].seq
+		}
+		return c.timers[i].when.Before(c.timers[j].when)
+	})
+	t := c.timers[0]
+	if t.when.After(end) {
+		return nil
+	}
+	c.timers = c.timers[1:]
+	if t.when.Before(c.now) {
+		t.when = c.now
+	}
+	return t
+}
@@ -0,0 +1,91 @@
+package cachetest_test
+
+import (
+	"testing"
+	"time"
+
+	cache "github.com/Code-Hex/go-generics-cache"
+	"github.com/Code-Hex/go-generics-cache/cachetest"
+)
+
+var _ cache.Clock = (*cachetest.FakeClock)(nil)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := cachetest.NewFakeClock(start)
+	if got := clk.Now(); !got.Equal(start) {
+		t.Fatalf("want %v but got %v", start, got)
+	}
+
+	var called []time.Duration
+	record := func() { called = append(called, clk.Now().Sub(start)) }
+	clk.AfterFunc(2*time.Second, record)
+	clk.AfterFunc(time.Second, record)
+	stop := clk.AfterFunc(3*time.Second, record)
+	clk.AfterFunc(time.Second, func() {
+		record()
+		// scheduled while advancing.
+		clk.AfterFunc(time.Second, record)
+	})
+
+	if !stop() {
+		t.Fatal("want the call is stopped")
+	}
+	if stop() {
+		t.Fatal("want the call has already been stopped")
+	}
+
+	clk.Advance(1500 * time.Millisecond)
+	if got := clk.Now().Sub(start); got != 1500*time.Millisecond {
+		t.Fatalf("want 1.5s elapsed but got %v", got)
+	}
+	if len(called) != 2 {
+		t.Fatalf("want 2 calls but got %v", called)
+	}
+
+	clk.Advance(time.Hour)
+	want := []time.Duration{time.Second, time.Second, 2 * time.Second, 2 * time.Second}
+	if len(called) != len(want) {
+		t.Fatalf("want calls at %v but got %v", want, called)
+	}
+	for i := range want {
+		if want[i] != called[i] {
+			t.Fatalf("want calls at %v but got %v", want, called)
+		}
+	}
+}
+
+func TestFakeClockWithCache(t *testing.T) {
+	clk := cachetest.NewFakeClock(time.Now())
+	var expired []string
+	c := cache.New(
+		cache.WithClock[string, int](clk),
+		cache.WithJanitorInterval[string, int](time.Minute),
+		cache.OnEvicted(func(key string, _ int, reason cache.EvictionReason) {
+			if reason == cache.EvictionReasonExpired {
+				expired = append(expired, key)
+			}
+		}),
+	)
+	defer c.Close()
+
+	c.Set("a", 1, cache.WithExpiration(time.Second))
+	c.Set("b", 2, cache.WithExpiration(2*time.Minute))
+
+	clk.Advance(2 * time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("want a is expired")
+	}
+	if len(expired) != 0 {
+		t.Fatalf("want the janitor does not run yet, but got %v", expired)
+	}
+
+	// the janitor runs.
+	clk.Advance(time.Minute)
+	if len(expired) != 1 || expired[0] != "a" {
+		t.Fatalf("want a is deleted by the janitor, but got %v", expired)
+	}
+	if got := c.Len(); got != 1 {
+		t.Fatalf("want length 1 but got %d", got)
+	}
+}
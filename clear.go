@@ -0,0 +1,39 @@
+package cache
+
+// Clear removes all items from the cache and stops tracking their expiration,
+// so that the cache is reset to the empty state. The removed items are not
+// reported to the OnEvicted callback. Use Purge to report them.
+//
+// It does nothing if the cache has been closed.
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	c.clear()
+}
+
+// Purge is like Clear, but reports the removed items to the OnEvicted
+// callback with EvictionReasonDeleted.
+func (c *Cache[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.unlock()
+	if c.closed {
+		return
+	}
+	if c.tracksEvictions() {
+		for _, key := range c.cache.Keys() {
+			if item, ok := c.cache.Peek(key); ok {
+				c.evicted(key, item, EvictionReasonDeleted)
+			}
+		}
+	}
+	c.clear()
+}
+
+// clear removes all items. c.mu must be held.
+func (c *Cache[K, V]) clear() {
+	c.cache.Clear()
+	c.expManager.clear()
+}
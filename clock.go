@@ -0,0 +1,33 @@
+package cache
+
+import "time"
+
+// Clock is the source of time for the cache. It is used to calculate
+// expiration times of items and to run the janitor.
+//
+// Implementations must be safe for concurrent use.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// AfterFunc waits for the duration to elapse and then calls f.
+	// It returns a function which stops the call, reporting whether the
+	// call has been stopped before it is made as like (*time.Timer).Stop.
+	AfterFunc(d time.Duration, f func()) (stop func() bool)
+}
+
+// systemClock is a Clock using the time package.
+type systemClock struct{}
+
+var _ Clock = systemClock{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) AfterFunc(d time.Duration, f func()) func() bool {
+	return time.AfterFunc(d, f).Stop
+}
+
+// nowFuncSetter is implemented by cache policies which record the time
+// when items are referenced (e.g., LFU), so that they use the clock of the cache.
+type nowFuncSetter interface {
+	SetNowFunc(now func() time.Time)
+}
@@ -0,0 +1,36 @@
+package cache
+
+import "errors"
+
+// ErrClosed is returned by methods of the cache which has been closed.
+var ErrClosed = errors.New("cache: closed")
+
+// Close stops the janitor and deletes the expired items, calling the
+// OnEvicted callback for them. It returns ErrClosed if the cache has
+// already been closed.
+//
+// After Close, the cache behaves as an empty cache which ignores any
+// updates: Get and Contains report the key is not found, Set and Delete
+// do nothing, Keys returns nil and Len returns 0. GetOrLoad, Snapshot
+// and Restore return ErrClosed.
+func (c *Cache[K, V]) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	c.closed = true
+	keys := c.expiredKeys()
+	c.mu.Unlock()
+
+	c.janitor.stop()
+	c.janitor.wait()
+	c.deleteExpiredKeys(keys)
+	return nil
+}
+
+func (c *Cache[K, V]) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
@@ -0,0 +1,91 @@
+package cache_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	cache "github.com/Code-Hex/go-generics-cache"
+)
+
+func TestClose(t *testing.T) {
+	var expired []string
+	c := cache.New(
+		cache.WithJanitorInterval[string, int](time.Hour),
+		cache.OnEvicted(func(key string, _ int, reason cache.EvictionReason) {
+			if reason == cache.EvictionReasonExpired {
+				expired = append(expired, key)
+			}
+		}),
+	)
+	c.Set("a", 1, cache.WithExpiration(time.Millisecond))
+	c.Set("b", 2)
+	time.Sleep(10 * time.Millisecond)
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 1 || expired[0] != "a" {
+		t.Fatalf("want a is expired on close, but got %v", expired)
+	}
+	if err := c.Close(); !errors.Is(err, cache.ErrClosed) {
+		t.Fatalf("want ErrClosed but got %v", err)
+	}
+
+	c.Set("c", 3)
+	if _, ok := c.Get("b"); ok {
+		t.Error("want b is not found after closed")
+	}
+	if c.Contains("c") {
+		t.Error("want c is not stored after closed")
+	}
+	if got, loaded := c.GetOrSet("b", 4); got != 4 || loaded {
+		t.Errorf("want 4, false but got %d, %v", got, loaded)
+	}
+	if got := c.Len(); got != 0 {
+		t.Errorf("want length 0 but got %d", got)
+	}
+	if got := c.Keys(); got != nil {
+		t.Errorf("want nil keys but got %v", got)
+	}
+	_, err := c.GetOrLoad(context.Background(), "b", func(context.Context, string) (int, error) {
+		t.Error("loader must not be called")
+		return 0, nil
+	})
+	if !errors.Is(err, cache.ErrClosed) {
+		t.Errorf("want ErrClosed from GetOrLoad but got %v", err)
+	}
+	var buf bytes.Buffer
+	if err := c.Snapshot(&buf, nil); !errors.Is(err, cache.ErrClosed) {
+		t.Errorf("want ErrClosed from Snapshot but got %v", err)
+	}
+	if err := c.Restore(&buf, nil); !errors.Is(err, cache.ErrClosed) {
+		t.Errorf("want ErrClosed from Restore but got %v", err)
+	}
+}
+
+func TestCloseStopsJanitor(t *testing.T) {
+	before := runtime.NumGoroutine()
+	caches := make([]*cache.Cache[int, int], 10)
+	for i := range caches {
+		caches[i] = cache.New[int, int]()
+	}
+	sharded := cache.NewSharded[int, int](10)
+	for _, c := range caches {
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sharded.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sharded.Close(); !errors.Is(err, cache.ErrClosed) {
+		t.Fatalf("want ErrClosed but got %v", err)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Fatalf("want janitors are stopped, but goroutines increased from %d to %d", before, after)
+	}
+}
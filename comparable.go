@@ -0,0 +1,60 @@
+package cache
+
+// ComparableCache is a in-memory cache which is able to store only comparable
+// values. It provides CompareAndSwap and CompareAndDelete in addition to Cache.
+type ComparableCache[K comparable, V comparable] struct {
+	*Cache[K, V]
+}
+
+// NewComparable creates a new cache for comparable values.
+func NewComparable[K comparable, V comparable](opts ...Option[K, V]) *ComparableCache[K, V] {
+	return &ComparableCache[K, V]{
+		Cache: New(opts...),
+	}
+}
+
+// CompareAndSwap swaps the old and new values for key if the value stored in
+// the cache is equal to old. An expired item is treated as absent.
+// The swapped item keeps the options of the existing item, e.g. the expiration.
+// The swapped result reports whether the swap was performed.
+func (cc *ComparableCache[K, V]) CompareAndSwap(key K, old, new V) (swapped bool) {
+	c := cc.Cache
+	c.mu.Lock()
+	defer c.unlock()
+	if c.closed {
+		return false
+	}
+	item, ok := c.cache.Peek(key)
+	if !ok || item.Expired() || item.Value != old {
+		return false
+	}
+	c.evicted(key, item, EvictionReasonReplaced)
+	// copies the item to keep its options.
+	updated := *item
+	updated.Value = new
+	if c.weigher != nil {
+		updated.Cost = c.weigher(key, new)
+	}
+	c.cache.Set(key, &updated)
+	return true
+}
+
+// CompareAndDelete deletes the item for key if its value is equal to old.
+// An expired item is treated as absent.
+// The deleted result reports whether the item was deleted.
+func (cc *ComparableCache[K, V]) CompareAndDelete(key K, old V) (deleted bool) {
+	c := cc.Cache
+	c.mu.Lock()
+	defer c.unlock()
+	if c.closed {
+		return false
+	}
+	item, ok := c.cache.Peek(key)
+	if !ok || item.Expired() || item.Value != old {
+		return false
+	}
+	c.evicted(key, item, EvictionReasonDeleted)
+	c.cache.Delete(key)
+	c.expManager.remove(key)
+	return true
+}
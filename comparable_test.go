@@ -0,0 +1,91 @@
+package cache_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	cache "github.com/Code-Hex/go-generics-cache"
+	"github.com/Code-Hex/go-generics-cache/cachetest"
+)
+
+func TestCompareAndSwap(t *testing.T) {
+	clk := cachetest.NewFakeClock(time.Now())
+	c := cache.NewComparable(cache.WithClock[string, string](clk))
+
+	if c.CompareAndSwap("a", "", "x") {
+		t.Fatal("want absent key is not swapped")
+	}
+	c.Set("a", "x", cache.WithExpiration(time.Minute))
+	if c.CompareAndSwap("a", "y", "z") {
+		t.Fatal("want different value is not swapped")
+	}
+	if !c.CompareAndSwap("a", "x", "y") {
+		t.Fatal("want swapped")
+	}
+	if got, ok := c.Get("a"); got != "y" || !ok {
+		t.Fatalf("want y but got %q, %v", got, ok)
+	}
+
+	// the expiration is kept.
+	clk.Advance(2 * time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("want a has expired")
+	}
+	if c.CompareAndSwap("a", "y", "z") {
+		t.Fatal("want expired item is not swapped")
+	}
+}
+
+func TestCompareAndSwapConcurrently(t *testing.T) {
+	c := cache.NewComparable[string, int]()
+	c.Set("a", 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				old, _ := c.Get("a")
+				if c.CompareAndSwap("a", old, old+1) {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if got, _ := c.Get("a"); got != 50 {
+		t.Errorf("want 50 but got %d", got)
+	}
+}
+
+func TestCompareAndDelete(t *testing.T) {
+	clk := cachetest.NewFakeClock(time.Now())
+	var evicted []string
+	c := cache.NewComparable(
+		cache.WithClock[string, int](clk),
+		cache.OnEvicted(func(key string, _ int, reason cache.EvictionReason) {
+			evicted = append(evicted, key+":"+reason.String())
+		}),
+	)
+	c.Set("a", 1)
+	c.Set("b", 2, cache.WithExpiration(time.Minute))
+
+	if c.CompareAndDelete("a", 2) {
+		t.Fatal("want different value is not deleted")
+	}
+	if !c.CompareAndDelete("a", 1) {
+		t.Fatal("want deleted")
+	}
+	if c.Contains("a") {
+		t.Fatal("want a is deleted")
+	}
+
+	clk.Advance(2 * time.Minute)
+	if c.CompareAndDelete("b", 2) {
+		t.Fatal("want expired item is not deleted")
+	}
+	if len(evicted) == 0 || evicted[0] != "a:deleted" {
+		t.Errorf("want a is deleted but got %v", evicted)
+	}
+}
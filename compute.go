@@ -0,0 +1,126 @@
+package cache
+
+// ComputeOp is the operation which Compute performs with the value computed.
+type ComputeOp int
+
+const (
+	// ComputeOpKeep indicates the item is left as it is. The computed value is ignored.
+	ComputeOpKeep ComputeOp = iota
+	// ComputeOpReplace indicates the computed value is set to the cache,
+	// replacing any existing value.
+	ComputeOpReplace
+	// ComputeOpDelete indicates the item is deleted from the cache.
+	ComputeOpDelete
+)
+
+// WithKeepExpiration is an option for Compute, ComputeIfAbsent and ComputeIfPresent
+// to keep the expiration of the existing item when its value is replaced.
+// The other expiration options are applied only if the item does not exist.
+//
+// Without this option, the expiration is reset by the options as Set does.
+func WithKeepExpiration() ItemOption {
+	return func(o *itemOptions) {
+		o.keepExpiration = true
+	}
+}
+
+// Compute atomically computes a new value for the key from the current value
+// and performs op returned by f. exists reports whether the key is present
+// and has not expired, and old is the zero value if it does not exist.
+//
+// It returns the value of the key after the operation and whether the key
+// is present. f is called while the cache is locked, so f must not call
+// methods of the cache.
+//
+// If the cache has been closed, f is not called and it returns the zero
+// value and false.
+func (c *Cache[K, V]) Compute(key K, f func(old V, exists bool) (val V, op ComputeOp), opts ...ItemOption) (actual V, ok bool) {
+	c.mu.Lock()
+	defer c.unlock()
+	if c.closed {
+		return
+	}
+	return c.compute(key, f, opts)
+}
+
+// ComputeIfAbsent is like Compute, but f is called only if the key is not
+// present or has expired. Otherwise it returns the current value and true.
+func (c *Cache[K, V]) ComputeIfAbsent(key K, f func() (val V, op ComputeOp), opts ...ItemOption) (actual V, ok bool) {
+	return c.Compute(key, func(old V, exists bool) (V, ComputeOp) {
+		if exists {
+			return old, ComputeOpKeep
+		}
+		return f()
+	}, opts...)
+}
+
+// ComputeIfPresent is like Compute, but f is called only if the key is
+// present and has not expired. Otherwise it returns the zero value and false.
+func (c *Cache[K, V]) ComputeIfPresent(key K, f func(old V) (val V, op ComputeOp), opts ...ItemOption) (actual V, ok bool) {
+	return c.Compute(key, func(old V, exists bool) (V, ComputeOp) {
+		if !exists {
+			return old, ComputeOpKeep
+		}
+		return f(old)
+	}, opts...)
+}
+
+// compute is the implementation of Compute. c.mu must be held.
+func (c *Cache[K, V]) compute(key K, f func(old V, exists bool) (V, ComputeOp), opts []ItemOption) (actual V, ok bool) {
+	var old V
+	item, found := c.cache.Peek(key)
+	exists := found && !item.Expired()
+	if exists {
+		old = item.Value
+	}
+
+	val, op := f(old, exists)
+	switch op {
+	case ComputeOpReplace:
+		reason := EvictionReasonReplaced
+		if found && !exists {
+			reason = EvictionReasonExpired
+		}
+		if found {
+			c.evicted(key, item, reason)
+		}
+		updated := c.newItem(key, val, opts...)
+		if exists && keepsExpiration(opts) {
+			updated.inheritExpiration(item)
+		}
+		c.trackExpiration(key, updated)
+		c.cache.Set(key, updated)
+		return val, true
+	case ComputeOpDelete:
+		if found {
+			reason := EvictionReasonDeleted
+			if !exists {
+				reason = EvictionReasonExpired
+			}
+			c.evicted(key, item, reason)
+			c.cache.Delete(key)
+			c.expManager.remove(key)
+		}
+		return actual, false
+	}
+	return old, exists
+}
+
+// keepsExpiration reports whether opts contain WithKeepExpiration.
+func keepsExpiration(opts []ItemOption) bool {
+	o := new(itemOptions)
+	for _, optFunc := range opts {
+		optFunc(o)
+	}
+	return o.keepExpiration
+}
+
+// inheritExpiration copies the expiration settings of old to the item.
+func (item *Item[K, V]) inheritExpiration(old *Item[K, V]) {
+	item.Expiration = old.Expiration
+	item.SlidingExpiration = old.SlidingExpiration
+	item.MaxExpiration = old.MaxExpiration
+	item.StaleWhileRevalidate = old.StaleWhileRevalidate
+	item.StaleIfError = old.StaleIfError
+	item.ttl = old.ttl
+}
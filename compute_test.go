@@ -0,0 +1,146 @@
+package cache_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	cache "github.com/Code-Hex/go-generics-cache"
+	"github.com/Code-Hex/go-generics-cache/cachetest"
+)
+
+func TestCompute(t *testing.T) {
+	var evicted []string
+	c := cache.New(cache.OnEvicted(func(key string, _ []int, reason cache.EvictionReason) {
+		evicted = append(evicted, key+":"+reason.String())
+	}))
+	appendValue := func(v int) func(old []int, exists bool) ([]int, cache.ComputeOp) {
+		return func(old []int, exists bool) ([]int, cache.ComputeOp) {
+			return append(old, v), cache.ComputeOpReplace
+		}
+	}
+
+	if got, ok := c.Compute("a", appendValue(1)); len(got) != 1 || !ok {
+		t.Fatalf("want [1] but got %v, %v", got, ok)
+	}
+	if got, ok := c.Compute("a", appendValue(2)); len(got) != 2 || !ok {
+		t.Fatalf("want [1 2] but got %v, %v", got, ok)
+	}
+
+	got, ok := c.Compute("a", func(old []int, exists bool) ([]int, cache.ComputeOp) {
+		if !exists {
+			t.Errorf("want a exists")
+		}
+		return nil, cache.ComputeOpKeep
+	})
+	if len(got) != 2 || !ok {
+		t.Fatalf("want [1 2] but got %v, %v", got, ok)
+	}
+	got, ok = c.Compute("b", func(old []int, exists bool) ([]int, cache.ComputeOp) {
+		return []int{1}, cache.ComputeOpKeep
+	})
+	if got != nil || ok {
+		t.Fatalf("want b is not stored but got %v, %v", got, ok)
+	}
+
+	if _, ok := c.Compute("a", func(old []int, exists bool) ([]int, cache.ComputeOp) {
+		return nil, cache.ComputeOpDelete
+	}); ok {
+		t.Fatal("want a is deleted")
+	}
+	if c.Contains("a") {
+		t.Fatal("want a is deleted")
+	}
+
+	want := []string{"a:replaced", "a:deleted"}
+	if len(evicted) != len(want) || evicted[0] != want[0] || evicted[1] != want[1] {
+		t.Errorf("want evicted %v but got %v", want, evicted)
+	}
+}
+
+func TestComputeConcurrently(t *testing.T) {
+	c := cache.New[string, int]()
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Compute("a", func(old int, exists bool) (int, cache.ComputeOp) {
+				return old + 1, cache.ComputeOpReplace
+			})
+		}()
+	}
+	wg.Wait()
+	if got, _ := c.Get("a"); got != 100 {
+		t.Errorf("want 100 but got %d", got)
+	}
+}
+
+func TestComputeIfAbsent(t *testing.T) {
+	clk := cachetest.NewFakeClock(time.Now())
+	c := cache.New(cache.WithClock[string, int](clk))
+	c.Set("a", 1, cache.WithExpiration(time.Minute))
+
+	got, ok := c.ComputeIfAbsent("a", func() (int, cache.ComputeOp) {
+		t.Error("want f is not called")
+		return 2, cache.ComputeOpReplace
+	})
+	if got != 1 || !ok {
+		t.Fatalf("want 1 but got %d, %v", got, ok)
+	}
+
+	// the expired item is absent.
+	clk.Advance(2 * time.Minute)
+	got, ok = c.ComputeIfAbsent("a", func() (int, cache.ComputeOp) {
+		return 2, cache.ComputeOpReplace
+	})
+	if got != 2 || !ok {
+		t.Fatalf("want 2 but got %d, %v", got, ok)
+	}
+	if got, ok := c.Get("a"); got != 2 || !ok {
+		t.Fatalf("want 2 but got %d, %v", got, ok)
+	}
+}
+
+func TestComputeIfPresent(t *testing.T) {
+	c := cache.New[string, int]()
+	got, ok := c.ComputeIfPresent("a", func(old int) (int, cache.ComputeOp) {
+		t.Error("want f is not called")
+		return 2, cache.ComputeOpReplace
+	})
+	if got != 0 || ok {
+		t.Fatalf("want a is not present but got %d, %v", got, ok)
+	}
+
+	c.Set("a", 1)
+	got, ok = c.ComputeIfPresent("a", func(old int) (int, cache.ComputeOp) {
+		return old * 10, cache.ComputeOpReplace
+	})
+	if got != 10 || !ok {
+		t.Fatalf("want 10 but got %d, %v", got, ok)
+	}
+}
+
+func TestComputeExpiration(t *testing.T) {
+	clk := cachetest.NewFakeClock(time.Now())
+	c := cache.New(cache.WithClock[string, int](clk))
+	incr := func(old int, exists bool) (int, cache.ComputeOp) {
+		return old + 1, cache.ComputeOpReplace
+	}
+
+	// the expiration is applied to the new item.
+	c.Compute("a", incr, cache.WithExpiration(time.Minute), cache.WithKeepExpiration())
+	c.Compute("b", incr, cache.WithExpiration(time.Minute))
+
+	clk.Advance(50 * time.Second)
+	c.Compute("a", incr, cache.WithExpiration(time.Minute), cache.WithKeepExpiration())
+	c.Compute("b", incr, cache.WithExpiration(time.Minute))
+
+	clk.Advance(20 * time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("want a has expired because the expiration is kept")
+	}
+	if got, ok := c.Get("b"); got != 2 || !ok {
+		t.Errorf("want b is reset the expiration but got %d, %v", got, ok)
+	}
+}
@@ -0,0 +1,74 @@
+package cache
+
+// EvictionReason is the reason why an entry has been removed from the cache.
+type EvictionReason int
+
+const (
+	// EvictionReasonCapacity indicates the entry was evicted by the cache
+	// replacement policy to make room for other entries.
+	EvictionReasonCapacity EvictionReason = iota + 1
+	// EvictionReasonExpired indicates the entry was removed because it has expired.
+	EvictionReasonExpired
+	// EvictionReasonDeleted indicates the entry was removed by Delete.
+	EvictionReasonDeleted
+	// EvictionReasonReplaced indicates the entry was replaced by a new value.
+	EvictionReasonReplaced
+)
+
+// String implements fmt.Stringer.
+func (r EvictionReason) String() string {
+	switch r {
+	case EvictionReasonCapacity:
+		return "capacity"
+	case EvictionReasonExpired:
+		return "expired"
+	case EvictionReasonDeleted:
+		return "deleted"
+	case EvictionReasonReplaced:
+		return "replaced"
+	}
+	return "unknown"
+}
+
+// evictionNotifier is implemented by cache policies which are able to report
+// entries evicted by their replacement algorithm.
+type evictionNotifier[K comparable, V any] interface {
+	SetOnEvicted(f func(key K, val V))
+}
+
+type evictedEntry[K comparable, V any] struct {
+	key    K
+	val    V
+	reason EvictionReason
+}
+
+// tracksEvictions reports whether removed entries need to be reported
+// to the eviction callback or the statistics.
+func (c *Cache[K, V]) tracksEvictions() bool {
+	return c.onEvicted != nil || c.stats != nil
+}
+
+// evicted records the item removed from the cache. The recorded entries are
+// passed to the eviction callback by unlock. c.mu must be held.
+func (c *Cache[K, V]) evicted(key K, item *Item[K, V], reason EvictionReason) {
+	c.stats.evicted(reason)
+	if c.onEvicted == nil {
+		return
+	}
+	c.evictedEntries = append(c.evictedEntries, evictedEntry[K, V]{
+		key:    key,
+		val:    item.Value,
+		reason: reason,
+	})
+}
+
+// unlock unlocks c.mu and then calls the eviction callback with entries
+// which have been removed while the lock was held.
+func (c *Cache[K, V]) unlock() {
+	entries := c.evictedEntries
+	c.evictedEntries = nil
+	c.mu.Unlock()
+	for _, e := range entries {
+		c.onEvicted(e.key, e.val, e.reason)
+	}
+}
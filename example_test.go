@@ -1,6 +1,7 @@
 package cache_test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"time"
@@ -40,6 +41,21 @@ func ExampleNewContext() {
 	// 0 false
 }
 
+func ExampleCache_Close() {
+	c := cache.New[string, int]()
+	c.Set("a", 1)
+
+	// an internal janitor is stopped.
+	if err := c.Close(); err != nil {
+		panic(err)
+	}
+	fmt.Println(c.Get("a"))
+	fmt.Println(c.Close())
+	// Output:
+	// 0 false
+	// cache: closed
+}
+
 func ExampleAsClock() {
 	// use clock cache algorithm.
 	c := cache.New(cache.AsClock[string, int]())
@@ -78,6 +94,22 @@ func ExampleWithExpiration() {
 	// 0 false
 }
 
+func ExampleWithDefaultExpiration() {
+	exp := 250 * time.Millisecond
+	c := cache.New(cache.WithDefaultExpiration[string, int](exp))
+	c.Set("a", 1)
+	c.Set("b", 2, cache.WithNoExpiration())
+
+	// waiting expiration.
+	time.Sleep(exp + 100*time.Millisecond) // + buffer
+
+	fmt.Println(c.Get("a")) // expired
+	fmt.Println(c.Get("b"))
+	// Output:
+	// 0 false
+	// 2 true
+}
+
 func ExampleWithReferenceCount() {
 	c := cache.New(cache.AsLFU[string, int](lfu.WithCapacity(2)))
 
@@ -104,6 +136,38 @@ func ExampleWithReferenceCount() {
 	// 3 true
 }
 
+func ExampleOnEvicted() {
+	c := cache.New(
+		cache.AsLRU[string, int](lru.WithCapacity(1)),
+		cache.OnEvicted(func(key string, val int, reason cache.EvictionReason) {
+			fmt.Println(key, val, reason)
+		}),
+	)
+	c.Set("a", 1)
+	c.Set("a", 2)
+	c.Set("b", 3)
+	c.Delete("b")
+	// Output:
+	// a 1 replaced
+	// a 2 capacity
+	// b 3 deleted
+}
+
+func ExampleWithWeigher() {
+	c := cache.New(
+		cache.AsLRU[string, string](lru.WithMaxCost(10)),
+		cache.WithWeigher(func(key string, val string) int64 {
+			return int64(len(val))
+		}),
+	)
+	c.Set("a", "hello")
+	c.Set("b", "world")
+	c.Set("c", "!") // "a" is evicted to keep the total cost within 10
+	fmt.Println(c.Keys())
+	// Output:
+	// [b c]
+}
+
 func ExampleCache_Delete() {
 	c := cache.New(cache.AsMRU[string, int]())
 	c.Set("a", 1)
@@ -157,6 +221,83 @@ func ExampleCache_GetOrSet() {
 	// 1 true
 }
 
+func ExampleCache_GetOrLoad() {
+	c := cache.New(cache.AsLRU[string, int](lru.WithCapacity(10)))
+	loader := func(ctx context.Context, key string) (int, error) {
+		fmt.Println("loading", key)
+		return len(key), nil
+	}
+
+	val1, err1 := c.GetOrLoad(context.Background(), "hello", loader)
+	fmt.Println(val1, err1)
+	val2, err2 := c.GetOrLoad(context.Background(), "hello", loader) // cached
+	fmt.Println(val2, err2)
+	// Output:
+	// loading hello
+	// 5 <nil>
+	// 5 <nil>
+}
+
+func ExampleCache_Compute() {
+	c := cache.New[string, []string]()
+	add := func(v string) func(old []string, exists bool) ([]string, cache.ComputeOp) {
+		return func(old []string, exists bool) ([]string, cache.ComputeOp) {
+			return append(old, v), cache.ComputeOpReplace
+		}
+	}
+	c.Compute("a", add("x"))
+	fmt.Println(c.Compute("a", add("y")))
+	fmt.Println(c.Compute("a", func(old []string, exists bool) ([]string, cache.ComputeOp) {
+		return nil, cache.ComputeOpDelete
+	}))
+	// Output:
+	// [x y] true
+	// [] false
+}
+
+func ExampleCache_Stats() {
+	c := cache.New(cache.WithStats[string, int]())
+	c.Set("a", 1)
+	c.Get("a")
+	c.Get("b")
+	stats := c.Stats()
+	fmt.Println(stats.Hits, stats.Misses, stats.HitRatio)
+	// Output:
+	// 1 1 0.5
+}
+
+func ExampleNewSharded() {
+	// the capacity of LRU is applied to each shard.
+	c := cache.NewSharded(4, cache.AsLRU[string, int](lru.WithCapacity(10)))
+	c.Set("a", 1)
+	gota, aok := c.Get("a")
+	gotb, bok := c.Get("b")
+	fmt.Println(gota, aok)
+	fmt.Println(gotb, bok)
+	// Output:
+	// 1 true
+	// 0 false
+}
+
+func ExampleCache_Snapshot() {
+	c := cache.New(cache.AsLRU[string, int]())
+	c.Set("a", 1)
+	c.Set("b", 2, cache.WithExpiration(time.Hour))
+
+	var buf bytes.Buffer
+	if err := c.Snapshot(&buf, cache.JSONCodec); err != nil {
+		panic(err)
+	}
+
+	restored := cache.New(cache.AsLRU[string, int]())
+	if err := restored.Restore(&buf, cache.JSONCodec); err != nil {
+		panic(err)
+	}
+	fmt.Println(restored.Keys())
+	// Output:
+	// [a b]
+}
+
 func ExampleNewNumber() {
 	nc := cache.NewNumber[string, int]()
 	nc.Set("a", 1)
@@ -5,6 +5,29 @@ import (
 	"time"
 )
 
+// expirationTracker tracks expiration times of keys in the cache.
+type expirationTracker[K comparable] interface {
+	// update registers or updates the expiration time of the key.
+	update(key K, expiration time.Time)
+	// updateMany registers or updates the expiration times of the keys at once.
+	// expirations[i] is the expiration time of keys[i].
+	updateMany(keys []K, expirations []time.Time)
+	// remove unregisters the key.
+	remove(key K)
+	// clear unregisters all keys.
+	clear()
+	// len returns the number of registered keys.
+	len() int
+	// expired unregisters and returns keys which have expired at now.
+	expired(now time.Time) []K
+}
+
+var (
+	_ expirationTracker[int] = (*expirationManager[int])(nil)
+	_ expirationTracker[int] = (*timingWheel[int])(nil)
+)
+
+// expirationManager is an expirationTracker using a binary heap.
 type expirationManager[K comparable] struct {
 	queue   expirationQueue[K]
 	mapping map[K]*expirationKey[K]
@@ -33,15 +56,48 @@ func (m *expirationManager[K]) update(key K, expiration time.Time) {
 	}
 }
 
+// updateMany rebuilds the heap at once instead of fixing it for each key
+// if the number of the keys is not small compared to the heap.
+func (m *expirationManager[K]) updateMany(keys []K, expirations []time.Time) {
+	if len(keys) < m.queue.Len()/2 {
+		for i, key := range keys {
+			m.update(key, expirations[i])
+		}
+		return
+	}
+	for i, key := range keys {
+		if e, ok := m.mapping[key]; ok {
+			e.expiration = expirations[i]
+			continue
+		}
+		v := &expirationKey[K]{
+			key:        key,
+			expiration: expirations[i],
+		}
+		m.queue.Push(v)
+		m.mapping[key] = v
+	}
+	heap.Init(&m.queue)
+}
+
+func (m *expirationManager[K]) clear() {
+	m.queue = make(expirationQueue[K], 0)
+	m.mapping = make(map[K]*expirationKey[K])
+}
+
 func (m *expirationManager[K]) len() int {
 	return m.queue.Len()
 }
 
-func (m *expirationManager[K]) pop() K {
-	v := heap.Pop(&m.queue)
-	key := v.(*expirationKey[K]).key
-	delete(m.mapping, key)
-	return key
+func (m *expirationManager[K]) expired(now time.Time) []K {
+	var keys []K
+	for m.queue.Len() > 0 && now.After(m.queue[0].expiration) {
+		v := heap.Pop(&m.queue)
+		key := v.(*expirationKey[K]).key
+		delete(m.mapping, key)
+		keys = append(keys, key)
+	}
+	return keys
 }
 
 func (m *expirationManager[K]) remove(key K) {
@@ -0,0 +1,154 @@
+package hashutil
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+	"math"
+	"reflect"
+)
+
+// Hasher hashes keys of any comparable type.
+//
+// Keys of basic types are hashed without allocations. Other keys are hashed
+// by walking the value with reflection in the same way as the == operator
+// compares them: pointers, channels and unsafe pointers by their address,
+// structs and arrays by their fields and elements, and interfaces by their
+// dynamic type and value. So equal keys always have the same hash.
+type Hasher[K comparable] struct {
+	seed maphash.Seed
+	// mix is a seeded value used to mix integer keys.
+	mix uint64
+}
+
+// New creates a new Hasher with a random seed.
+func New[K comparable]() *Hasher[K] {
+	seed := maphash.MakeSeed()
+	var h maphash.Hash
+	h.SetSeed(seed)
+	return &Hasher[K]{
+		seed: seed,
+		mix:  h.Sum64(),
+	}
+}
+
+// Hash returns the hash of the key.
+func (h *Hasher[K]) Hash(key K) uint64 {
+	switch k := any(key).(type) {
+	case string:
+		return h.hashString(k)
+	case int:
+		return h.hashUint64(uint64(k))
+	case int8:
+		return h.hashUint64(uint64(k))
+	case int16:
+		return h.hashUint64(uint64(k))
+	case int32:
+		return h.hashUint64(uint64(k))
+	case int64:
+		return h.hashUint64(uint64(k))
+	case uint:
+		return h.hashUint64(uint64(k))
+	case uint8:
+		return h.hashUint64(uint64(k))
+	case uint16:
+		return h.hashUint64(uint64(k))
+	case uint32:
+		return h.hashUint64(uint64(k))
+	case uint64:
+		return h.hashUint64(k)
+	case uintptr:
+		return h.hashUint64(uint64(k))
+	case float32:
+		return h.hashUint64(floatBits(float64(k)))
+	case float64:
+		return h.hashUint64(floatBits(k))
+	case bool:
+		if k {
+			return h.hashUint64(1)
+		}
+		return h.hashUint64(0)
+	}
+	var mh maphash.Hash
+	mh.SetSeed(h.seed)
+	writeValue(&mh, reflect.ValueOf(&key).Elem())
+	return mh.Sum64()
+}
+
+// writeValue writes the value to mh so that equal values write the same bytes.
+func writeValue(mh *maphash.Hash, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			writeUint64(mh, 1)
+		} else {
+			writeUint64(mh, 0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint64(mh, uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint64(mh, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeUint64(mh, floatBits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		writeUint64(mh, floatBits(real(c)))
+		writeUint64(mh, floatBits(imag(c)))
+	case reflect.String:
+		s := v.String()
+		writeUint64(mh, uint64(len(s)))
+		mh.WriteString(s)
+	case reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		writeUint64(mh, uint64(v.Pointer()))
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			writeValue(mh, v.Index(i))
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			// blank fields are ignored by the == operator.
+			if t.Field(i).Name == "_" {
+				continue
+			}
+			writeValue(mh, v.Field(i))
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			writeUint64(mh, 0)
+			return
+		}
+		e := v.Elem()
+		mh.WriteString(e.Type().String())
+		writeValue(mh, e)
+	}
+}
+
+func writeUint64(mh *maphash.Hash, x uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], x)
+	mh.Write(b[:])
+}
+
+// floatBits returns the bits of f. The negative zero is treated as the
+// positive zero since they are equal.
+func floatBits(f float64) uint64 {
+	if f == 0 {
+		return 0
+	}
+	return math.Float64bits(f)
+}
+
+func (h *Hasher[K]) hashString(s string) uint64 {
+	var mh maphash.Hash
+	mh.SetSeed(h.seed)
+	mh.WriteString(s)
+	return mh.Sum64()
+}
+
+// hashUint64 mixes the integer by the finalizer of splitmix64.
+func (h *Hasher[K]) hashUint64(x uint64) uint64 {
+	x ^= h.mix
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
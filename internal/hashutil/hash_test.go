@@ -0,0 +1,126 @@
+package hashutil
+
+import (
+	"hash/maphash"
+	"math"
+	"reflect"
+	"testing"
+)
+
+type key struct {
+	a int
+	b string
+}
+
+func TestHash(t *testing.T) {
+	t.Run("string", func(t *testing.T) {
+		h := New[string]()
+		if h.Hash("foo") != h.Hash("foo") {
+			t.Errorf("want equal keys have the same hash")
+		}
+		if h.Hash("foo") == h.Hash("bar") {
+			t.Errorf("want different keys have different hashes")
+		}
+	})
+
+	t.Run("int", func(t *testing.T) {
+		h := New[int]()
+		if h.Hash(1) != h.Hash(1) {
+			t.Errorf("want equal keys have the same hash")
+		}
+		if h.Hash(1) == h.Hash(2) {
+			t.Errorf("want different keys have different hashes")
+		}
+	})
+
+	t.Run("struct", func(t *testing.T) {
+		h := New[key]()
+		if h.Hash(key{a: 1, b: "foo"}) != h.Hash(key{a: 1, b: "foo"}) {
+			t.Errorf("want equal keys have the same hash")
+		}
+		if h.Hash(key{a: 1, b: "foo"}) == h.Hash(key{a: 2, b: "foo"}) {
+			t.Errorf("want different keys have different hashes")
+		}
+	})
+}
+
+func TestHashEqualKeys(t *testing.T) {
+	t.Run("pointer", func(t *testing.T) {
+		h := New[*key]()
+		p := &key{a: 1}
+		want := h.Hash(p)
+		// the pointer is hashed by its address, not by its pointee.
+		p.a = 2
+		if got := h.Hash(p); want != got {
+			t.Errorf("want the same hash after the pointee is modified")
+		}
+		if h.Hash(p) == h.Hash(&key{a: 2}) {
+			t.Errorf("want different pointers have different hashes")
+		}
+	})
+
+	t.Run("negative zero", func(t *testing.T) {
+		h := New[float64]()
+		if h.Hash(0.0) != h.Hash(math.Copysign(0, -1)) {
+			t.Errorf("want 0 and -0 have the same hash")
+		}
+		h32 := New[float32]()
+		if h32.Hash(0) != h32.Hash(float32(math.Copysign(0, -1))) {
+			t.Errorf("want 0 and -0 have the same hash")
+		}
+	})
+
+	t.Run("named", func(t *testing.T) {
+		type id string
+		h := New[id]()
+		if h.Hash("foo") != h.Hash("foo") {
+			t.Errorf("want equal keys have the same hash")
+		}
+		if h.Hash("foo") == h.Hash("bar") {
+			t.Errorf("want different keys have different hashes")
+		}
+	})
+
+	t.Run("struct", func(t *testing.T) {
+		type compound struct {
+			p *int
+			f float64
+			a [2]string
+			_ int
+		}
+		h := New[compound]()
+		n := 1
+		k1 := compound{p: &n, f: 0, a: [2]string{"foo"}}
+		k2 := compound{p: &n, f: math.Copysign(0, -1), a: [2]string{"foo"}}
+		if k1 != k2 {
+			t.Fatal("want the keys are equal")
+		}
+		if h.Hash(k1) != h.Hash(k2) {
+			t.Errorf("want equal keys have the same hash")
+		}
+		if h.Hash(k1) == h.Hash(compound{p: &n, a: [2]string{"bar"}}) {
+			t.Errorf("want different keys have different hashes")
+		}
+	})
+
+	t.Run("interface", func(t *testing.T) {
+		// interface keys satisfy comparable since Go 1.20, so they are
+		// hashed by writeValue directly.
+		seed := maphash.MakeSeed()
+		hash := func(key interface{}) uint64 {
+			var mh maphash.Hash
+			mh.SetSeed(seed)
+			writeValue(&mh, reflect.ValueOf(&key).Elem())
+			return mh.Sum64()
+		}
+		if hash(nil) != hash(nil) {
+			t.Errorf("want equal keys have the same hash")
+		}
+		if hash(1) != hash(1) || hash([2]int{1, 2}) != hash([2]int{1, 2}) {
+			t.Errorf("want equal keys have the same hash")
+		}
+		if hash(1) == hash("1") || hash(1) == hash(nil) {
+			t.Errorf("want different keys have different hashes")
+		}
+	})
+}
@@ -10,15 +10,25 @@ import (
 type janitor struct {
 	ctx      context.Context
 	interval time.Duration
+	clock    Clock
 	done     chan struct{}
 	once     sync.Once
+	// finished is closed when the janitor goroutine has returned.
+	finished chan struct{}
+
+	// mu protects the fields below.
+	mu        sync.Mutex
+	stopped   bool
+	stopTimer func() bool
 }
 
-func newJanitor(ctx context.Context, interval time.Duration) *janitor {
+func newJanitor(ctx context.Context, interval time.Duration, clock Clock) *janitor {
 	j := &janitor{
 		ctx:      ctx,
 		interval: interval,
+		clock:    clock,
 		done:     make(chan struct{}),
+		finished: make(chan struct{}),
 	}
 	return j
 }
@@ -28,21 +38,39 @@ func (j *janitor) stop() {
 	j.once.Do(func() { close(j.done) })
 }
 
+// wait waits for the janitor to finish after stopped.
+func (j *janitor) wait() {
+	<-j.finished
+}
+
 // run with the given cleanup callback function.
+// The cleanup is called every interval by the clock.
 func (j *janitor) run(cleanup func()) {
+	var tick func()
+	schedule := func() {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		if !j.stopped {
+			j.stopTimer = j.clock.AfterFunc(j.interval, tick)
+		}
+	}
+	tick = func() {
+		cleanup()
+		schedule()
+	}
+	schedule()
+
 	go func() {
-		ticker := time.NewTicker(j.interval)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ticker.C:
-				cleanup()
-			case <-j.done:
-				cleanup() // last call
-				return
-			case <-j.ctx.Done():
-				j.stop()
-			}
+		defer close(j.finished)
+		select {
+		case <-j.done:
+		case <-j.ctx.Done():
+			j.stop()
 		}
+		j.mu.Lock()
+		j.stopped = true
+		j.stopTimer()
+		j.mu.Unlock()
+		cleanup() // last call
 	}()
 }
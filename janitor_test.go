@@ -11,7 +11,7 @@ func TestJanitor(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	janitor := newJanitor(ctx, time.Millisecond)
+	janitor := newJanitor(ctx, time.Millisecond, systemClock{})
 
 	checkDone := make(chan struct{})
 	janitor.done = checkDone
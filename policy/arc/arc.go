@@ -0,0 +1,353 @@
+package arc
+
+import (
+	"container/list"
+
+	"github.com/Code-Hex/go-generics-cache/policy/internal/policyutil"
+)
+
+// Cache is used an ARC (Adaptive replacement cache) cache replacement policy.
+//
+// ARC keeps track of both frequently used and recently used items, plus a
+// recent eviction history for both. The resident items are split into two
+// LRU lists, T1 for items seen once recently and T2 for items seen at least
+// twice. B1 and B2 are "ghost" lists which hold only the keys evicted from
+// T1 and T2. A hit on a ghost list adapts the target size of T1 (p), so
+// the cache balances between recency and frequency depending on workload.
+type Cache[K comparable, V any] struct {
+	cap     int
+	maxCost int64
+	cost    int64
+	// p is the target size of T1.
+	p int
+
+	t1 *list.List // resident, recently used once
+	t2 *list.List // resident, frequently used
+	b1 *list.List // ghost, evicted from t1
+	b2 *list.List // ghost, evicted from t2
+
+	items  map[K]*list.Element // elements in t1 or t2
+	ghosts map[K]*list.Element // elements in b1 or b2
+
+	onEvicted func(key K, val V)
+}
+
+type entry[K comparable, V any] struct {
+	key  K
+	val  V
+	cost int64
+	// list is the list which the entry belongs to.
+	list *list.List
+}
+
+// Option is an option for ARC cache.
+type Option func(*options)
+
+type options struct {
+	capacity int
+	maxCost  int64
+}
+
+func newOptions() *options {
+	return &options{
+		capacity: 128,
+	}
+}
+
+// WithCapacity is an option to set cache capacity.
+func WithCapacity(cap int) Option {
+	return func(o *options) {
+		o.capacity = cap
+	}
+}
+
+// WithMaxCost is an option to set the maximum total cost of items in the cache.
+// Items are evicted until the total cost is within maxCost in addition to the capacity.
+//
+// The cost of an item is the value of GetCost() method if the value satisfies
+// "interface{ GetCost() int64 }", otherwise 1. An item whose cost is greater
+// than maxCost is never stored.
+//
+// The default is 0, which means the total cost is not limited.
+func WithMaxCost(maxCost int64) Option {
+	return func(o *options) {
+		o.maxCost = maxCost
+	}
+}
+
+// NewCache creates a new non-thread safe ARC cache whose capacity is the default size (128).
+// If the capacity is less than 1, it is treated as 1.
+func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
+	o := newOptions()
+	for _, optFunc := range opts {
+		optFunc(o)
+	}
+	if o.capacity < 1 {
+		o.capacity = 1
+	}
+	return &Cache[K, V]{
+		cap:     o.capacity,
+		maxCost: o.maxCost,
+		t1:      list.New(),
+		t2:      list.New(),
+		b1:      list.New(),
+		b2:      list.New(),
+		items:   make(map[K]*list.Element, o.capacity),
+		ghosts:  make(map[K]*list.Element, o.capacity),
+	}
+}
+
+// Get looks up a key's value from the cache.
+func (c *Cache[K, V]) Get(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	// the item is used at least twice. promotes it to T2.
+	e = c.move(e, c.t2)
+	c.items[key] = e
+	return e.Value.(*entry[K, V]).val, true
+}
+
+// Peek looks up a key's value from the cache without promoting
+// the item to T2.
+func (c *Cache[K, V]) Peek(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	return e.Value.(*entry[K, V]).val, true
+}
+
+// Set sets a value to the cache with key. replacing any existing value.
+//
+// If the cache is created with WithMaxCost option, items are evicted by
+// the replacement policy until the total cost is within the max cost.
+func (c *Cache[K, V]) Set(key K, val V) {
+	cost := policyutil.GetCost(val)
+	if c.maxCost > 0 && cost > c.maxCost {
+		// the item never fits in the cache.
+		c.Delete(key)
+		if c.onEvicted != nil {
+			c.onEvicted(key, val)
+		}
+		return
+	}
+
+	if e, ok := c.items[key]; ok {
+		ent := e.Value.(*entry[K, V])
+		if c.overCost(cost - ent.cost) {
+			// takes the item out so that it is not evicted to make room for itself.
+			c.remove(e)
+			c.replaceForCost(cost)
+			ent.list = c.t2
+			e = c.t2.PushFront(ent)
+		} else {
+			e = c.move(e, c.t2)
+			c.cost -= ent.cost
+		}
+		ent.val = val
+		ent.cost = cost
+		c.cost += cost
+		c.items[key] = e
+		return
+	}
+
+	if e, ok := c.ghosts[key]; ok {
+		ent := e.Value.(*entry[K, V])
+		inB2 := ent.list == c.b2
+		// adapts the target size of T1.
+		if inB2 {
+			c.p = maxInt(0, c.p-maxInt(c.b1.Len()/c.b2.Len(), 1))
+		} else {
+			c.p = minInt(c.cap, c.p+maxInt(c.b2.Len()/c.b1.Len(), 1))
+		}
+		delete(c.ghosts, key)
+		ent.list.Remove(e)
+		if c.full() {
+			c.replace(inB2)
+		}
+		c.replaceForCost(cost)
+		ent.val = val
+		ent.cost = cost
+		c.cost += cost
+		ent.list = c.t2
+		c.items[key] = c.t2.PushFront(ent)
+		return
+	}
+
+	if c.t1.Len()+c.b1.Len() >= c.cap {
+		if c.t1.Len() < c.cap {
+			c.removeGhost(c.b1)
+			if c.full() {
+				c.replace(false)
+			}
+		} else {
+			c.evict(c.t1.Back())
+		}
+	} else if c.full() {
+		if c.t1.Len()+c.t2.Len()+c.b1.Len()+c.b2.Len() >= 2*c.cap {
+			c.removeGhost(c.b2)
+		}
+		c.replace(false)
+	}
+	c.replaceForCost(cost)
+
+	ent := &entry[K, V]{
+		key:  key,
+		val:  val,
+		cost: cost,
+		list: c.t1,
+	}
+	c.items[key] = c.t1.PushFront(ent)
+	c.cost += cost
+}
+
+// SetOnEvicted sets a callback which is called with the entry evicted
+// by the replacement policy when the cache is over the capacity.
+func (c *Cache[K, V]) SetOnEvicted(f func(key K, val V)) {
+	c.onEvicted = f
+}
+
+// Keys returns the keys of the cache. the order is from oldest to newest in
+// T1 (recently used once), followed by from oldest to newest in T2 (frequently used).
+func (c *Cache[K, V]) Keys() []K {
+	keys := make([]K, 0, len(c.items))
+	for _, l := range []*list.List{c.t1, c.t2} {
+		for e := l.Back(); e != nil; e = e.Prev() {
+			keys = append(keys, e.Value.(*entry[K, V]).key)
+		}
+	}
+	return keys
+}
+
+// Delete deletes the item with provided key from the cache.
+func (c *Cache[K, V]) Delete(key K) {
+	if e, ok := c.items[key]; ok {
+		c.remove(e)
+	}
+	if e, ok := c.ghosts[key]; ok {
+		e.Value.(*entry[K, V]).list.Remove(e)
+		delete(c.ghosts, key)
+	}
+}
+
+// Clear removes all items and ghost entries from the cache without calling
+// the eviction callback. The adaptive target size of T1 is also reset.
+func (c *Cache[K, V]) Clear() {
+	c.p = 0
+	c.cost = 0
+	c.t1.Init()
+	c.t2.Init()
+	c.b1.Init()
+	c.b2.Init()
+	c.items = make(map[K]*list.Element, c.cap)
+	c.ghosts = make(map[K]*list.Element, c.cap)
+}
+
+// Len returns the number of items in the cache.
+func (c *Cache[K, V]) Len() int {
+	return len(c.items)
+}
+
+func (c *Cache[K, V]) full() bool {
+	return c.t1.Len()+c.t2.Len() >= c.cap
+}
+
+// replace evicts an item from T1 or T2 to make room, and remembers the key
+// in the corresponding ghost list.
+func (c *Cache[K, V]) replace(inB2 bool) {
+	t1Len := c.t1.Len()
+	if t1Len > 0 && (c.t2.Len() == 0 || t1Len > c.p || (inB2 && t1Len == c.p)) {
+		c.demote(c.t1.Back(), c.b1)
+	} else {
+		c.demote(c.t2.Back(), c.b2)
+	}
+}
+
+// replaceForCost evicts items by replace until the additional cost fits
+// in the max cost. The ghost lists are trimmed so that they do not grow
+// beyond the capacity, since the resident items can be fewer than the
+// capacity when the cost is limited.
+func (c *Cache[K, V]) replaceForCost(additional int64) {
+	for c.t1.Len()+c.t2.Len() > 0 && c.overCost(additional) {
+		c.replace(false)
+		for c.b1.Len()+c.b2.Len() > c.cap {
+			if c.b2.Len() > 0 {
+				c.removeGhost(c.b2)
+			} else {
+				c.removeGhost(c.b1)
+			}
+		}
+	}
+}
+
+// overCost reports whether the total cost with the additional cost exceeds the max cost.
+func (c *Cache[K, V]) overCost(additional int64) bool {
+	return c.maxCost > 0 && c.cost+additional > c.maxCost
+}
+
+// demote evicts the resident element and moves its key to the ghost list.
+func (c *Cache[K, V]) demote(e *list.Element, ghost *list.List) {
+	ent := e.Value.(*entry[K, V])
+	c.evict(e)
+	var zero V
+	ent.val = zero // ghost entries keep only keys
+	ent.cost = 0
+	ent.list = ghost
+	c.ghosts[ent.key] = ghost.PushFront(ent)
+}
+
+// evict removes the resident element from the cache, and reports it to
+// the eviction callback.
+func (c *Cache[K, V]) evict(e *list.Element) {
+	ent := c.remove(e)
+	if c.onEvicted != nil {
+		c.onEvicted(ent.key, ent.val)
+	}
+}
+
+// remove removes the resident element from the cache.
+func (c *Cache[K, V]) remove(e *list.Element) *entry[K, V] {
+	ent := e.Value.(*entry[K, V])
+	ent.list.Remove(e)
+	delete(c.items, ent.key)
+	c.cost -= ent.cost
+	return ent
+}
+
+// removeGhost removes the oldest key from the ghost list.
+func (c *Cache[K, V]) removeGhost(ghost *list.List) {
+	e := ghost.Back()
+	if e == nil {
+		return
+	}
+	ghost.Remove(e)
+	delete(c.ghosts, e.Value.(*entry[K, V]).key)
+}
+
+// move moves the element to the front of the list.
+func (c *Cache[K, V]) move(e *list.Element, to *list.List) *list.Element {
+	ent := e.Value.(*entry[K, V])
+	if ent.list == to {
+		to.MoveToFront(e)
+		return e
+	}
+	ent.list.Remove(e)
+	ent.list = to
+	return to.PushFront(ent)
+}
+
+func minInt(x, y int) int {
+	if x < y {
+		return x
+	}
+	return y
+}
+
+func maxInt(x, y int) int {
+	if x > y {
+		return x
+	}
+	return y
+}
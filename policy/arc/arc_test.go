@@ -0,0 +1,296 @@
+package arc_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Code-Hex/go-generics-cache/policy/arc"
+)
+
+func TestSet(t *testing.T) {
+	// set capacity is 1
+	cache := arc.NewCache[string, int](arc.WithCapacity(1))
+	cache.Set("foo", 1)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got, ok := cache.Get("foo"); got != 1 || !ok {
+		t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+	}
+
+	// if over the cap
+	cache.Set("bar", 2)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	bar, ok := cache.Get("bar")
+	if bar != 2 || !ok {
+		t.Fatalf("invalid value bar %d, cachehit %v", bar, ok)
+	}
+
+	// checks deleted oldest
+	if _, ok := cache.Get("foo"); ok {
+		t.Fatalf("invalid delete oldest value foo %v", ok)
+	}
+
+	// valid: if over the cap but same key
+	cache.Set("bar", 100)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	bar, ok = cache.Get("bar")
+	if bar != 100 || !ok {
+		t.Fatalf("invalid replacing value bar %d, cachehit %v", bar, ok)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cache := arc.NewCache[string, int](arc.WithCapacity(1))
+	cache.Set("foo", 1)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+
+	cache.Delete("foo2")
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length after deleted does not exist key: %d", got)
+	}
+
+	cache.Delete("foo")
+	if got := cache.Len(); got != 0 {
+		t.Fatalf("invalid length after deleted: %d", got)
+	}
+	if _, ok := cache.Get("foo"); ok {
+		t.Fatalf("invalid get after deleted %v", ok)
+	}
+}
+
+func TestZeroCap(t *testing.T) {
+	for _, cap := range []int{0, -1} {
+		cache := arc.NewCache[string, int](arc.WithCapacity(cap))
+		cache.Set("foo", 1)
+		cache.Set("bar", 2)
+		cache.Set("foo", 3) // ghost hit
+
+		// treated as 1.
+		if got := cache.Len(); got != 1 {
+			t.Fatalf("WithCapacity(%d): invalid length: %d", cap, got)
+		}
+		if got, ok := cache.Get("foo"); got != 3 || !ok {
+			t.Fatalf("WithCapacity(%d): invalid value got %d, cachehit %v", cap, got, ok)
+		}
+	}
+}
+
+func TestKeys(t *testing.T) {
+	cache := arc.NewCache[string, int]()
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Set("baz", 3)
+	cache.Get("bar") // promotes to T2
+	cache.Set("foo", 4)
+
+	got := strings.Join(cache.Keys(), ",")
+	want := strings.Join([]string{
+		"baz", // T1
+		"bar", // T2
+		"foo",
+	}, ",")
+	if got != want {
+		t.Errorf("want %q, but got %q", want, got)
+	}
+	if len(cache.Keys()) != cache.Len() {
+		t.Errorf("want number of keys %d, but got %d", len(cache.Keys()), cache.Len())
+	}
+}
+
+func TestScanResistance(t *testing.T) {
+	cache := arc.NewCache[string, int](arc.WithCapacity(4))
+	// hot items are used frequently.
+	for _, key := range []string{"hot1", "hot2"} {
+		cache.Set(key, 0)
+		cache.Get(key)
+	}
+
+	// one-time scan must not flush frequently used items.
+	for i := 0; i < 100; i++ {
+		cache.Set(strconv.Itoa(i), i)
+	}
+
+	for _, key := range []string{"hot1", "hot2"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("want %q is not evicted by scan", key)
+		}
+	}
+	if got := cache.Len(); got != 4 {
+		t.Errorf("invalid length: %d", got)
+	}
+}
+
+func TestGhostHit(t *testing.T) {
+	cache := arc.NewCache[string, int](arc.WithCapacity(2))
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Set("baz", 3) // "foo" is evicted to B1
+
+	if _, ok := cache.Get("foo"); ok {
+		t.Fatalf("want evicted foo is not found")
+	}
+
+	// ghost hit. "foo" is recently evicted so it is inserted to T2.
+	cache.Set("foo", 4)
+	if got, ok := cache.Get("foo"); got != 4 || !ok {
+		t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+	}
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
+
+func TestSetOnEvicted(t *testing.T) {
+	cache := arc.NewCache[string, int](arc.WithCapacity(2))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, val int) {
+		evicted = append(evicted, key+"="+strconv.Itoa(val))
+	})
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Get("foo")
+	cache.Set("foo", 3) // replacing is not an eviction
+	cache.Set("baz", 4)
+	cache.Delete("foo") // deletion is not an eviction
+
+	got := strings.Join(evicted, ",")
+	if want := "bar=2"; want != got {
+		t.Errorf("want evicted %q, but got %q", want, got)
+	}
+}
+
+type weighted int
+
+func (w weighted) GetCost() int64 { return int64(w) }
+
+func TestMaxCost(t *testing.T) {
+	cache := arc.NewCache[string, weighted](arc.WithMaxCost(10))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ weighted) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("foo", 4)
+	cache.Set("bar", 4)
+	cache.Set("baz", 4) // over the max cost
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+
+	// never stored because it exceeds the max cost by itself.
+	cache.Set("huge", 11)
+	if _, ok := cache.Get("huge"); ok {
+		t.Fatalf("want huge is rejected")
+	}
+
+	// replacing with a heavier value evicts the other items.
+	cache.Set("bar", 10)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got, ok := cache.Get("bar"); got != 10 || !ok {
+		t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+	}
+
+	got := strings.Join(evicted, ",")
+	if want := "foo,huge,baz"; want != got {
+		t.Errorf("want evicted %q, but got %q", want, got)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	if _, ok := arc.NewCache[string, int]().Peek("foo"); ok {
+		t.Fatalf("want foo is not found")
+	}
+
+	cases := []struct {
+		name   string
+		access func(c *arc.Cache[string, int], key string) (int, bool)
+		want   string
+	}{
+		{
+			// getting "foo" promotes it to T2, so "bar" is replaced in T1.
+			name:   "get",
+			access: (*arc.Cache[string, int]).Get,
+			want:   "bar",
+		},
+		{
+			// peeking "foo" leaves it in T1 as the least recently used.
+			name:   "peek",
+			access: (*arc.Cache[string, int]).Peek,
+			want:   "foo",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cache := arc.NewCache[string, int](arc.WithCapacity(2))
+			var evicted []string
+			cache.SetOnEvicted(func(key string, _ int) {
+				evicted = append(evicted, key)
+			})
+			cache.Set("foo", 1)
+			cache.Set("bar", 2)
+			for i := 0; i < 3; i++ {
+				if got, ok := tc.access(cache, "foo"); got != 1 || !ok {
+					t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+				}
+			}
+			cache.Set("baz", 3)
+			if got := strings.Join(evicted, ","); got != tc.want {
+				t.Errorf("want evicted %q, but got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestClear(t *testing.T) {
+	cache := arc.NewCache[string, int](arc.WithCapacity(3))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Set("baz", 3)
+	cache.Get("baz")
+	cache.Get("bar")
+	cache.Set("qux", 4)  // "foo" is evicted to B1
+	cache.Set("quux", 5) // "qux" is evicted to B1
+	cache.Set("foo", 6)  // hits B1 and increases the target size of T1
+	evicted = nil
+	cache.Clear()
+	if got := cache.Len(); got != 0 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := cache.Keys(); len(got) != 0 {
+		t.Fatalf("want no keys, but got %v", got)
+	}
+	if len(evicted) != 0 {
+		t.Fatalf("want clearing is not an eviction, but got %v", evicted)
+	}
+
+	// the ghost lists are also cleared, so "qux" which was in B1 is
+	// inserted to T1 instead of T2.
+	cache.Set("qux", 7)
+	cache.Set("foo", 8)
+	cache.Set("bar", 9)
+	cache.Get("foo")
+	cache.Get("bar")
+	if got, want := strings.Join(cache.Keys(), ","), "qux,foo,bar"; got != want {
+		t.Errorf("want keys %q, but got %q", want, got)
+	}
+
+	// the target size of T1 is also reset to 0, so the item in T1 is
+	// replaced rather than the one in T2.
+	cache.Set("baz", 10)
+	if got, want := strings.Join(evicted, ","), "qux"; got != want {
+		t.Errorf("want evicted %q, but got %q", want, got)
+	}
+}
@@ -20,12 +20,17 @@ type Cache[K comparable, V any] struct {
 	hand     *ring.Ring
 	head     *ring.Ring
 	capacity int
+	maxCost  int64
+	cost     int64
+
+	onEvicted func(key K, val V)
 }
 
 type entry[K comparable, V any] struct {
 	key            K
 	val            V
 	referenceCount int
+	cost           int64
 }
 
 // Option is an option for clock cache.
@@ -33,6 +38,7 @@ type Option func(*options)
 
 type options struct {
 	capacity int
+	maxCost  int64
 }
 
 func newOptions() *options {
@@ -48,6 +54,20 @@ func WithCapacity(cap int) Option {
 	}
 }
 
+// WithMaxCost is an option to set the maximum total cost of items in the cache.
+// Items are evicted until the total cost is within maxCost in addition to the capacity.
+//
+// The cost of an item is the value of GetCost() method if the value satisfies
+// "interface{ GetCost() int64 }", otherwise 1. An item whose cost is greater
+// than maxCost is never stored.
+//
+// The default is 0, which means the total cost is not limited.
+func WithMaxCost(maxCost int64) Option {
+	return func(o *options) {
+		o.maxCost = maxCost
+	}
+}
+
 // NewCache creates a new non-thread safe clock cache whose capacity is the default size (128).
 func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
 	o := newOptions()
@@ -60,6 +80,7 @@ func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
 		hand:     r,
 		head:     r,
 		capacity: o.capacity,
+		maxCost:  o.maxCost,
 	}
 }
 
@@ -67,21 +88,46 @@ func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
 //
 // If value satisfies "interface{ GetReferenceCount() int }", the value of
 // the GetReferenceCount() method is used to set the initial value of reference count.
+//
+// If the cache is created with WithMaxCost option, items are evicted in the clock
+// order until the total cost is within the max cost.
 func (c *Cache[K, V]) Set(key K, val V) {
+	cost := policyutil.GetCost(val)
+	if c.maxCost > 0 && cost > c.maxCost {
+		// the item never fits in the cache.
+		c.Delete(key)
+		if c.onEvicted != nil {
+			c.onEvicted(key, val)
+		}
+		return
+	}
+
 	if e, ok := c.items[key]; ok {
 		entry := e.Value.(*entry[K, V])
-		entry.referenceCount++
-		entry.val = val
-		return
+		if !c.overCost(cost - entry.cost) {
+			entry.referenceCount++
+			entry.val = val
+			c.cost += cost - entry.cost
+			entry.cost = cost
+			return
+		}
+		// the new value does not fit in place. stores it as a new item
+		// so that the item itself is not evicted to make room.
+		c.Delete(key)
+	}
+	for len(c.items) > 0 && c.overCost(cost) {
+		c.evictUnreferenced()
 	}
 	c.evict()
 	c.hand.Value = &entry[K, V]{
 		key:            key,
 		val:            val,
 		referenceCount: policyutil.GetReferenceCount(val),
+		cost:           cost,
 	}
 	c.items[key] = c.hand
 	c.hand = c.hand.Next()
+	c.cost += cost
 }
 
 // Get looks up a key's value from the cache.
@@ -95,36 +141,113 @@ func (c *Cache[K, V]) Get(key K) (zero V, _ bool) {
 	return entry.val, true
 }
 
+// Peek looks up a key's value from the cache without incrementing
+// the reference count of the item.
+func (c *Cache[K, V]) Peek(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	return e.Value.(*entry[K, V]).val, true
+}
+
+// Resize changes the capacity of the cache by rebuilding the ring. If the
+// number of items exceeds the new capacity, items are evicted in the clock
+// order and reported to the eviction callback. It returns the number of
+// evicted items. If cap is less than 1, it is treated as 1.
+//
+// The items are placed in the new ring in the order from the hand, so the
+// order of Keys may change.
+func (c *Cache[K, V]) Resize(cap int) (evicted int) {
+	if cap < 1 {
+		cap = 1
+	}
+	for len(c.items) > cap {
+		c.evictUnreferenced()
+		evicted++
+	}
+
+	r := ring.New(cap)
+	slot := r
+	c.hand.Do(func(v any) {
+		if v == nil {
+			return
+		}
+		entry := v.(*entry[K, V])
+		slot.Value = entry
+		c.items[entry.key] = slot
+		slot = slot.Next()
+	})
+	c.head = r
+	// the hand points to the first empty slot, or the oldest item if full.
+	c.hand = slot
+	c.capacity = cap
+	return evicted
+}
+
+// SetOnEvicted sets a callback which is called with the entry evicted
+// by the replacement policy when the cache is over the capacity.
+func (c *Cache[K, V]) SetOnEvicted(f func(key K, val V)) {
+	c.onEvicted = f
+}
+
 func (c *Cache[K, V]) evict() {
 	for c.hand.Value != nil && c.hand.Value.(*entry[K, V]).referenceCount > 0 {
 		c.hand.Value.(*entry[K, V]).referenceCount--
 		c.hand = c.hand.Next()
 	}
-	if c.hand.Value != nil {
+	c.removeHand()
+}
+
+// evictUnreferenced advances the hand to the next item whose reference count
+// is zero, skipping empty slots, and evicts it. The hand is left on the
+// emptied slot. The cache must not be empty.
+func (c *Cache[K, V]) evictUnreferenced() {
+	for {
+		if c.hand.Value == nil {
+			c.hand = c.hand.Next()
+			continue
+		}
 		entry := c.hand.Value.(*entry[K, V])
-		delete(c.items, entry.key)
-		c.hand.Value = nil
+		if entry.referenceCount == 0 {
+			break
+		}
+		entry.referenceCount--
+		c.hand = c.hand.Next()
+	}
+	c.removeHand()
+}
+
+// removeHand removes the item which the hand points to and reports it to
+// the eviction callback.
+func (c *Cache[K, V]) removeHand() {
+	if c.hand.Value == nil {
+		return
+	}
+	entry := c.hand.Value.(*entry[K, V])
+	delete(c.items, entry.key)
+	c.hand.Value = nil
+	c.cost -= entry.cost
+	if c.onEvicted != nil {
+		c.onEvicted(entry.key, entry.val)
 	}
 }
 
+// overCost reports whether the total cost with the additional cost exceeds the max cost.
+func (c *Cache[K, V]) overCost(additional int64) bool {
+	return c.maxCost > 0 && c.cost+additional > c.maxCost
+}
+
 // Keys returns the keys of the cache. the order as same as current ring order.
 func (c *Cache[K, V]) Keys() []K {
 	keys := make([]K, 0, len(c.items))
-	r := c.head
-	if r.Value == nil {
-		return []K{}
-	}
-	// the first element
-	keys = append(keys, r.Value.(*entry[K, V]).key)
-
-	// iterating
-	for p := c.head.Next(); p != r; p = p.Next() {
-		if p.Value == nil {
-			continue
+	c.head.Do(func(v any) {
+		// skips the slots of deleted items.
+		if v == nil {
+			return
 		}
-		e := p.Value.(*entry[K, V])
-		keys = append(keys, e.key)
-	}
+		keys = append(keys, v.(*entry[K, V]).key)
+	})
 	return keys
 }
 
@@ -132,10 +255,24 @@ func (c *Cache[K, V]) Keys() []K {
 func (c *Cache[K, V]) Delete(key K) {
 	if e, ok := c.items[key]; ok {
 		delete(c.items, key)
+		c.cost -= e.Value.(*entry[K, V]).cost
 		e.Value = nil
 	}
 }
 
+// Clear removes all items from the cache without calling the eviction callback.
+// The hand is reset to the head of the ring.
+func (c *Cache[K, V]) Clear() {
+	r := c.head
+	for n := c.head.Len(); n > 0; n-- {
+		r.Value = nil
+		r = r.Next()
+	}
+	c.hand = c.head
+	c.items = make(map[K]*ring.Ring, c.capacity)
+	c.cost = 0
+}
+
 // Len returns the number of items in the cache.
 func (c *Cache[K, V]) Len() int {
 	return len(c.items)
@@ -118,6 +118,18 @@ func TestKeys(t *testing.T) {
 		}
 	})
 
+	t.Run("with deletion of the head", func(t *testing.T) {
+		cache := clock.NewCache[string, int](clock.WithCapacity(4))
+		cache.Set("foo", 1)
+		cache.Set("bar", 2)
+		cache.Delete("foo")
+
+		got := strings.Join(cache.Keys(), ",")
+		if want := "bar"; got != want {
+			t.Errorf("want %q, but got %q", want, got)
+		}
+	})
+
 	t.Run("with deletion", func(t *testing.T) {
 		cache := clock.NewCache[string, int](clock.WithCapacity(4))
 		cache.Set("foo", 1)
@@ -175,3 +187,194 @@ func TestIssue29(t *testing.T) {
 		t.Errorf("want keys %q, but got keys %q", wantKeys, gotKeys)
 	}
 }
+
+func TestSetOnEvicted(t *testing.T) {
+	cache := clock.NewCache[string, int](clock.WithCapacity(2))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, val int) {
+		evicted = append(evicted, key+"="+strconv.Itoa(val))
+	})
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Get("foo")
+	cache.Set("foo", 3) // replacing is not an eviction
+	cache.Set("baz", 4)
+	cache.Delete("foo") // deletion is not an eviction
+	got := strings.Join(evicted, ",")
+	if want := "bar=2"; want != got {
+		t.Errorf("want evicted %q, but got %q", want, got)
+	}
+}
+
+type weighted int
+
+func (w weighted) GetCost() int64 { return int64(w) }
+
+func TestMaxCost(t *testing.T) {
+	cache := clock.NewCache[string, weighted](clock.WithMaxCost(10))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ weighted) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("foo", 4)
+	cache.Set("bar", 4)
+	cache.Set("baz", 4) // over the max cost
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+
+	// never stored because it exceeds the max cost by itself.
+	cache.Set("huge", 11)
+	if _, ok := cache.Get("huge"); ok {
+		t.Fatalf("want huge is rejected")
+	}
+
+	// replacing with a heavier value evicts the other items.
+	cache.Set("bar", 10)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got, ok := cache.Get("bar"); got != 10 || !ok {
+		t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+	}
+
+	got := strings.Join(evicted, ",")
+	if want := "foo,huge,baz"; want != got {
+		t.Errorf("want evicted %q, but got %q", want, got)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	if _, ok := clock.NewCache[string, int]().Peek("foo"); ok {
+		t.Fatalf("want foo is not found")
+	}
+
+	cases := []struct {
+		name   string
+		access func(c *clock.Cache[string, int], key string) (int, bool)
+		want   string
+	}{
+		{
+			// getting "foo" increases the reference count, so the hand passes it.
+			name:   "get",
+			access: (*clock.Cache[string, int]).Get,
+			want:   "bar",
+		},
+		{
+			// peeking "foo" does not increase the reference count.
+			name:   "peek",
+			access: (*clock.Cache[string, int]).Peek,
+			want:   "foo",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cache := clock.NewCache[string, int](clock.WithCapacity(2))
+			var evicted []string
+			cache.SetOnEvicted(func(key string, _ int) {
+				evicted = append(evicted, key)
+			})
+			cache.Set("foo", 1)
+			cache.Set("bar", 2)
+			for i := 0; i < 3; i++ {
+				if got, ok := tc.access(cache, "foo"); got != 1 || !ok {
+					t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+				}
+			}
+			cache.Set("baz", 3)
+			if got := strings.Join(evicted, ","); got != tc.want {
+				t.Errorf("want evicted %q, but got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestResize(t *testing.T) {
+	cache := clock.NewCache[string, int](clock.WithCapacity(3))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Set("baz", 3)
+	if got := cache.Resize(1); got != 2 {
+		t.Fatalf("want 2 evicted items, but got %d", got)
+	}
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := strings.Join(evicted, ","); got != "foo,bar" {
+		t.Errorf("want evicted %q, but got %q", "foo,bar", got)
+	}
+
+	// growing keeps the items.
+	if got := cache.Resize(3); got != 0 {
+		t.Fatalf("want no evicted items, but got %d", got)
+	}
+	cache.Set("qux", 4)
+	cache.Set("quux", 5)
+	if got := cache.Len(); got != 3 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := len(evicted); got != 2 {
+		t.Errorf("want no more evictions, but got %v", evicted)
+	}
+	if got := strings.Join(cache.Keys(), ","); got != "baz,qux,quux" {
+		t.Errorf("want keys %q, but got %q", "baz,qux,quux", got)
+	}
+	if got, ok := cache.Get("baz"); got != 3 || !ok {
+		t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+	}
+}
+
+func TestResizeLessThanOne(t *testing.T) {
+	for _, cap := range []int{0, -1} {
+		cache := clock.NewCache[string, int](clock.WithCapacity(3))
+		cache.Set("foo", 1)
+		cache.Set("bar", 2)
+		// treated as 1.
+		if got := cache.Resize(cap); got != 1 {
+			t.Fatalf("Resize(%d): want 1 evicted item, but got %d", cap, got)
+		}
+		cache.Set("baz", 3)
+		if got := cache.Len(); got != 1 {
+			t.Fatalf("Resize(%d): invalid length: %d", cap, got)
+		}
+		if got, ok := cache.Get("baz"); got != 3 || !ok {
+			t.Fatalf("Resize(%d): invalid value got %d, cachehit %v", cap, got, ok)
+		}
+	}
+}
+
+func TestClear(t *testing.T) {
+	cache := clock.NewCache[string, int](clock.WithCapacity(3))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Get("foo")
+	cache.Clear()
+	if got := cache.Len(); got != 0 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := cache.Keys(); len(got) != 0 {
+		t.Fatalf("want no keys, but got %v", got)
+	}
+	if len(evicted) != 0 {
+		t.Fatalf("want clearing is not an eviction, but got %v", evicted)
+	}
+
+	// the hand is back to the head, so the items are placed from the head.
+	cache.Set("baz", 3)
+	cache.Set("qux", 4)
+	cache.Set("quux", 5)
+	if got, want := strings.Join(cache.Keys(), ","), "baz,qux,quux"; got != want {
+		t.Errorf("want keys %q, but got %q", want, got)
+	}
+	if len(evicted) != 0 {
+		t.Fatalf("want no evictions, but got %v", evicted)
+	}
+}
@@ -2,6 +2,8 @@ package fifo
 
 import (
 	"container/list"
+
+	"github.com/Code-Hex/go-generics-cache/policy/internal/policyutil"
 )
 
 // Cache is used a FIFO (First in first out) cache replacement policy.
@@ -12,11 +14,16 @@ type Cache[K comparable, V any] struct {
 	items    map[K]*list.Element
 	queue    *list.List // keys
 	capacity int
+	maxCost  int64
+	cost     int64
+
+	onEvicted func(key K, val V)
 }
 
 type entry[K comparable, V any] struct {
-	key K
-	val V
+	key  K
+	val  V
+	cost int64
 }
 
 // Option is an option for FIFO cache.
@@ -24,6 +31,7 @@ type Option func(*options)
 
 type options struct {
 	capacity int
+	maxCost  int64
 }
 
 func newOptions() *options {
@@ -39,6 +47,20 @@ func WithCapacity(cap int) Option {
 	}
 }
 
+// WithMaxCost is an option to set the maximum total cost of items in the cache.
+// Items are evicted until the total cost is within maxCost in addition to the capacity.
+//
+// The cost of an item is the value of GetCost() method if the value satisfies
+// "interface{ GetCost() int64 }", otherwise 1. An item whose cost is greater
+// than maxCost is never stored.
+//
+// The default is 0, which means the total cost is not limited.
+func WithMaxCost(maxCost int64) Option {
+	return func(o *options) {
+		o.maxCost = maxCost
+	}
+}
+
 // NewCache creates a new non-thread safe FIFO cache whose capacity is the default size (128).
 func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
 	o := newOptions()
@@ -49,22 +71,62 @@ func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
 		items:    make(map[K]*list.Element, o.capacity),
 		queue:    list.New(),
 		capacity: o.capacity,
+		maxCost:  o.maxCost,
 	}
 }
 
 // Set sets any item to the cache. replacing any existing item.
+//
+// If the cache is created with WithMaxCost option, the first entered
+// items are evicted until the total cost is within the max cost.
 func (c *Cache[K, V]) Set(key K, val V) {
-	if c.queue.Len() == c.capacity {
-		e := c.dequeue()
-		delete(c.items, e.Value.(*entry[K, V]).key)
+	cost := policyutil.GetCost(val)
+	if c.maxCost > 0 && cost > c.maxCost {
+		// the item never fits in the cache.
+		c.Delete(key)
+		if c.onEvicted != nil {
+			c.onEvicted(key, val)
+		}
+		return
 	}
+
 	c.Delete(key) // delete old key if already exists specified key.
+	if c.queue.Len() == c.capacity {
+		c.evict()
+	}
+	for c.queue.Len() > 0 && c.overCost(cost) {
+		c.evict()
+	}
 	entry := &entry[K, V]{
-		key: key,
-		val: val,
+		key:  key,
+		val:  val,
+		cost: cost,
 	}
 	e := c.queue.PushBack(entry)
 	c.items[key] = e
+	c.cost += cost
+}
+
+// Resize changes the capacity of the cache. If the number of items exceeds
+// the new capacity, the first entered items are evicted and reported to the
+// eviction callback. It returns the number of evicted items.
+// If cap is less than 1, it is treated as 1.
+func (c *Cache[K, V]) Resize(cap int) (evicted int) {
+	if cap < 1 {
+		cap = 1
+	}
+	c.capacity = cap
+	for c.queue.Len() > 0 && c.queue.Len() > cap {
+		c.evict()
+		evicted++
+	}
+	return evicted
+}
+
+// SetOnEvicted sets a callback which is called with the entry evicted
+// by the replacement policy when the cache is over the capacity.
+func (c *Cache[K, V]) SetOnEvicted(f func(key K, val V)) {
+	c.onEvicted = f
 }
 
 // Get gets an item from the cache.
@@ -77,6 +139,16 @@ func (c *Cache[K, V]) Get(k K) (val V, ok bool) {
 	return got.Value.(*entry[K, V]).val, true
 }
 
+// Peek gets an item from the cache. It is the same as Get
+// because the order of FIFO is not affected by lookups.
+func (c *Cache[K, V]) Peek(k K) (val V, ok bool) {
+	got, found := c.items[k]
+	if !found {
+		return
+	}
+	return got.Value.(*entry[K, V]).val, true
+}
+
 // Keys returns cache keys.
 func (c *Cache[K, V]) Keys() []K {
 	keys := make([]K, 0, len(c.items))
@@ -91,9 +163,17 @@ func (c *Cache[K, V]) Delete(key K) {
 	if e, ok := c.items[key]; ok {
 		c.queue.Remove(e)
 		delete(c.items, key)
+		c.cost -= e.Value.(*entry[K, V]).cost
 	}
 }
 
+// Clear removes all items from the cache without calling the eviction callback.
+func (c *Cache[K, V]) Clear() {
+	c.queue.Init()
+	c.items = make(map[K]*list.Element, c.capacity)
+	c.cost = 0
+}
+
 // Len returns the number of items in the cache.
 func (c *Cache[K, V]) Len() int {
 	return c.queue.Len()
@@ -104,3 +184,19 @@ func (c *Cache[K, V]) dequeue() *list.Element {
 	c.queue.Remove(e)
 	return e
 }
+
+// evict evicts the first entered item and reports it to the eviction callback.
+func (c *Cache[K, V]) evict() {
+	e := c.dequeue()
+	entry := e.Value.(*entry[K, V])
+	delete(c.items, entry.key)
+	c.cost -= entry.cost
+	if c.onEvicted != nil {
+		c.onEvicted(entry.key, entry.val)
+	}
+}
+
+// overCost reports whether the total cost with the additional cost exceeds the max cost.
+func (c *Cache[K, V]) overCost(additional int64) bool {
+	return c.maxCost > 0 && c.cost+additional > c.maxCost
+}
@@ -1,6 +1,7 @@
 package fifo_test
 
 import (
+	"strconv"
 	"strings"
 	"testing"
 
@@ -86,3 +87,206 @@ func TestKeys(t *testing.T) {
 		t.Errorf("want number of keys %d, but got %d", len(cache.Keys()), cache.Len())
 	}
 }
+
+func TestSetOnEvicted(t *testing.T) {
+	cache := fifo.NewCache[string, int](fifo.WithCapacity(2))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, val int) {
+		evicted = append(evicted, key+"="+strconv.Itoa(val))
+	})
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Get("foo")
+	cache.Set("baz", 3)
+	cache.Delete("bar") // deletion is not an eviction
+	got := strings.Join(evicted, ",")
+	if want := "foo=1"; want != got {
+		t.Errorf("want evicted %q, but got %q", want, got)
+	}
+}
+
+type weighted int
+
+func (w weighted) GetCost() int64 { return int64(w) }
+
+func TestMaxCost(t *testing.T) {
+	cache := fifo.NewCache[string, weighted](fifo.WithMaxCost(10))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ weighted) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("foo", 4)
+	cache.Set("bar", 4)
+	cache.Set("baz", 4) // over the max cost
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+
+	// never stored because it exceeds the max cost by itself.
+	cache.Set("huge", 11)
+	if _, ok := cache.Get("huge"); ok {
+		t.Fatalf("want huge is rejected")
+	}
+
+	// replacing with a heavier value evicts the other items.
+	cache.Set("bar", 10)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got, ok := cache.Get("bar"); got != 10 || !ok {
+		t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+	}
+
+	got := strings.Join(evicted, ",")
+	if want := "foo,huge,baz"; want != got {
+		t.Errorf("want evicted %q, but got %q", want, got)
+	}
+}
+
+func TestSetExistingKeyAtCapacity(t *testing.T) {
+	cache := fifo.NewCache[string, int](fifo.WithCapacity(2))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Set("bar", 3) // replacing does not evict "foo"
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if len(evicted) != 0 {
+		t.Fatalf("want no evictions, but got %v", evicted)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	if _, ok := fifo.NewCache[string, int]().Peek("foo"); ok {
+		t.Fatalf("want foo is not found")
+	}
+
+	cases := []struct {
+		name   string
+		access func(c *fifo.Cache[string, int], key string) (int, bool)
+		want   string
+	}{
+		{
+			// neither getting nor peeking changes the first entered item.
+			name:   "get",
+			access: (*fifo.Cache[string, int]).Get,
+			want:   "foo",
+		},
+		{
+			// peeking is the same as getting in FIFO.
+			name:   "peek",
+			access: (*fifo.Cache[string, int]).Peek,
+			want:   "foo",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cache := fifo.NewCache[string, int](fifo.WithCapacity(2))
+			var evicted []string
+			cache.SetOnEvicted(func(key string, _ int) {
+				evicted = append(evicted, key)
+			})
+			cache.Set("foo", 1)
+			cache.Set("bar", 2)
+			for i := 0; i < 3; i++ {
+				if got, ok := tc.access(cache, "foo"); got != 1 || !ok {
+					t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+				}
+			}
+			cache.Set("baz", 3)
+			if got := strings.Join(evicted, ","); got != tc.want {
+				t.Errorf("want evicted %q, but got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestResize(t *testing.T) {
+	cache := fifo.NewCache[string, int](fifo.WithCapacity(3))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Set("baz", 3)
+	if got := cache.Resize(1); got != 2 {
+		t.Fatalf("want 2 evicted items, but got %d", got)
+	}
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := strings.Join(evicted, ","); got != "foo,bar" {
+		t.Errorf("want evicted %q, but got %q", "foo,bar", got)
+	}
+
+	// growing keeps the items.
+	if got := cache.Resize(3); got != 0 {
+		t.Fatalf("want no evicted items, but got %d", got)
+	}
+	cache.Set("qux", 4)
+	cache.Set("quux", 5)
+	if got := cache.Len(); got != 3 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := len(evicted); got != 2 {
+		t.Errorf("want no more evictions, but got %v", evicted)
+	}
+}
+
+func TestResizeLessThanOne(t *testing.T) {
+	for _, cap := range []int{0, -1} {
+		cache := fifo.NewCache[string, int](fifo.WithCapacity(3))
+		cache.Set("foo", 1)
+		cache.Set("bar", 2)
+		// treated as 1.
+		if got := cache.Resize(cap); got != 1 {
+			t.Fatalf("Resize(%d): want 1 evicted item, but got %d", cap, got)
+		}
+		cache.Set("baz", 3)
+		if got := cache.Len(); got != 1 {
+			t.Fatalf("Resize(%d): invalid length: %d", cap, got)
+		}
+		if got, ok := cache.Get("baz"); got != 3 || !ok {
+			t.Fatalf("Resize(%d): invalid value got %d, cachehit %v", cap, got, ok)
+		}
+	}
+}
+
+func TestClear(t *testing.T) {
+	cache := fifo.NewCache[string, int](fifo.WithCapacity(10), fifo.WithMaxCost(2))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Get("foo")
+	cache.Clear()
+	if got := cache.Len(); got != 0 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := cache.Keys(); len(got) != 0 {
+		t.Fatalf("want no keys, but got %v", got)
+	}
+	if len(evicted) != 0 {
+		t.Fatalf("want clearing is not an eviction, but got %v", evicted)
+	}
+
+	// the total cost is also reset, so two items fit without eviction.
+	cache.Set("baz", 3)
+	cache.Set("qux", 4)
+	if len(evicted) != 0 {
+		t.Fatalf("want no evictions, but got %v", evicted)
+	}
+
+	// the queue before clearing is dropped, so "baz" is the first entered.
+	cache.Set("quux", 5)
+	if got := strings.Join(evicted, ","); got != "baz" {
+		t.Errorf("want evicted %q, but got %q", "baz", got)
+	}
+}
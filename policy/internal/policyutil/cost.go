@@ -0,0 +1,9 @@
+package policyutil
+
+// GetCost gets cost from cache value.
+func GetCost(v any) int64 {
+	if getter, ok := v.(interface{ GetCost() int64 }); ok {
+		return getter.GetCost()
+	}
+	return 1
+}
@@ -0,0 +1,41 @@
+package policyutil
+
+import (
+	"testing"
+)
+
+type coster struct {
+	cost int64
+}
+
+func (c coster) GetCost() int64 {
+	return c.cost
+}
+
+func TestGetCost(t *testing.T) {
+	tests := []struct {
+		name  string
+		input any
+		want  int64
+	}{
+		{
+			name:  "with GetCost() method",
+			input: coster{cost: 5},
+			want:  5,
+		},
+		{
+			name:  "without GetCost() method",
+			input: "sample string",
+			want:  1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output := GetCost(test.input)
+			if output != test.want {
+				t.Errorf("want %d, got %d", test.want, output)
+			}
+		})
+	}
+}
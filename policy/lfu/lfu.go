@@ -2,6 +2,9 @@ package lfu
 
 import (
 	"container/heap"
+	"time"
+
+	"github.com/Code-Hex/go-generics-cache/policy/internal/policyutil"
 )
 
 // Cache is used a LFU (Least-frequently used) cache replacement policy.
@@ -11,9 +14,14 @@ import (
 // a block was accessed, we store the value of how many times it was accessed. So of course
 // while running an access sequence we will replace a block which was used fewest times from our cache.
 type Cache[K comparable, V any] struct {
-	cap   int
-	queue *priorityQueue[K, V]
-	items map[K]*entry[K, V]
+	cap     int
+	maxCost int64
+	cost    int64
+	queue   *priorityQueue[K, V]
+	items   map[K]*entry[K, V]
+
+	onEvicted func(key K, val V)
+	now       func() time.Time
 }
 
 // Option is an option for LFU cache.
@@ -21,6 +29,7 @@ type Option func(*options)
 
 type options struct {
 	capacity int
+	maxCost  int64
 }
 
 func newOptions() *options {
@@ -36,6 +45,20 @@ func WithCapacity(cap int) Option {
 	}
 }
 
+// WithMaxCost is an option to set the maximum total cost of items in the cache.
+// Items are evicted until the total cost is within maxCost in addition to the capacity.
+//
+// The cost of an item is the value of GetCost() method if the value satisfies
+// "interface{ GetCost() int64 }", otherwise 1. An item whose cost is greater
+// than maxCost is never stored.
+//
+// The default is 0, which means the total cost is not limited.
+func WithMaxCost(maxCost int64) Option {
+	return func(o *options) {
+		o.maxCost = maxCost
+	}
+}
+
 // NewCache creates a new non-thread safe LFU cache whose capacity is the default size (128).
 func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
 	o := newOptions()
@@ -43,9 +66,11 @@ func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
 		optFunc(o)
 	}
 	return &Cache[K, V]{
-		cap:   o.capacity,
-		queue: newPriorityQueue[K, V](o.capacity),
-		items: make(map[K]*entry[K, V], o.capacity),
+		cap:     o.capacity,
+		maxCost: o.maxCost,
+		queue:   newPriorityQueue[K, V](o.capacity),
+		items:   make(map[K]*entry[K, V], o.capacity),
+		now:     time.Now,
 	}
 }
 
@@ -55,31 +80,124 @@ func (c *Cache[K, V]) Get(key K) (zero V, _ bool) {
 	if !ok {
 		return
 	}
-	e.referenced()
+	e.referenced(c.now())
 	heap.Fix(c.queue, e.index)
 	return e.val, true
 }
 
+// Peek looks up a key's value from the cache without incrementing
+// the reference count of the item.
+func (c *Cache[K, V]) Peek(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	return e.val, true
+}
+
 // Set sets a value to the cache with key. replacing any existing value.
 //
 // If value satisfies "interface{ GetReferenceCount() int }", the value of
 // the GetReferenceCount() method is used to set the initial value of reference count.
+//
+// If the cache is created with WithMaxCost option, the least frequently used
+// items are evicted until the total cost is within the max cost.
 func (c *Cache[K, V]) Set(key K, val V) {
+	cost := policyutil.GetCost(val)
+	if c.maxCost > 0 && cost > c.maxCost {
+		// the item never fits in the cache.
+		c.Delete(key)
+		if c.onEvicted != nil {
+			c.onEvicted(key, val)
+		}
+		return
+	}
+
 	if e, ok := c.items[key]; ok {
-		c.queue.update(e, val)
+		// take out the entry while evicting so that it is not evicted itself.
+		heap.Remove(c.queue, e.index)
+		c.cost -= e.cost
+		for c.queue.Len() > 0 && c.overCost(cost) {
+			c.evict()
+		}
+		e.cost = cost
+		c.cost += cost
+		heap.Push(c.queue, e)
+		c.queue.update(e, val, c.now())
 		return
 	}
 
 	if len(c.items) == c.cap {
-		evictedEntry := heap.Pop(c.queue)
-		if evictedEntry != nil {
-			delete(c.items, evictedEntry.(*entry[K, V]).key)
-		}
+		c.evict()
+	}
+	for c.queue.Len() > 0 && c.overCost(cost) {
+		c.evict()
 	}
 
-	e := newEntry(key, val)
+	e := newEntry(key, val, c.now())
+	e.cost = cost
 	heap.Push(c.queue, e)
 	c.items[key] = e
+	c.cost += cost
+}
+
+// SetMany sets multiple values to the cache. replacing any existing values.
+//
+// It is equivalent to calling Set for each item, but if all of the items
+// fit in the cache without eviction, the priority queue is rebuilt at once
+// instead of being fixed for each item.
+func (c *Cache[K, V]) SetMany(items map[K]V) {
+	if c.maxCost > 0 || len(c.items)+len(items) > c.cap {
+		for key, val := range items {
+			c.Set(key, val)
+		}
+		return
+	}
+	now := c.now()
+	for key, val := range items {
+		cost := policyutil.GetCost(val)
+		if e, ok := c.items[key]; ok {
+			e.val = val
+			e.referenced(now)
+			c.cost += cost - e.cost
+			e.cost = cost
+			continue
+		}
+		e := newEntry(key, val, now)
+		e.cost = cost
+		c.queue.Push(e)
+		c.items[key] = e
+		c.cost += cost
+	}
+	heap.Init(c.queue)
+}
+
+// Resize changes the capacity of the cache. If the number of items exceeds
+// the new capacity, the least frequently used items are evicted and reported
+// to the eviction callback. It returns the number of evicted items.
+// If cap is less than 1, it is treated as 1.
+func (c *Cache[K, V]) Resize(cap int) (evicted int) {
+	if cap < 1 {
+		cap = 1
+	}
+	c.cap = cap
+	for c.queue.Len() > 0 && c.queue.Len() > cap {
+		c.evict()
+		evicted++
+	}
+	return evicted
+}
+
+// SetOnEvicted sets a callback which is called with the entry evicted
+// by the replacement policy when the cache is over the capacity.
+func (c *Cache[K, V]) SetOnEvicted(f func(key K, val V)) {
+	c.onEvicted = f
+}
+
+// SetNowFunc sets a function which returns the current time to record
+// when items are referenced. Default is time.Now.
+func (c *Cache[K, V]) SetNowFunc(now func() time.Time) {
+	c.now = now
 }
 
 // Keys returns the keys of the cache. the order is from oldest to newest.
@@ -96,10 +214,71 @@ func (c *Cache[K, V]) Delete(key K) {
 	if e, ok := c.items[key]; ok {
 		heap.Remove(c.queue, e.index)
 		delete(c.items, key)
+		c.cost -= e.cost
+	}
+}
+
+// DeleteMany deletes the items with provided keys from the cache.
+//
+// It is equivalent to calling Delete for each key, but if the number of
+// the keys is not small compared to the cache, the priority queue is
+// rebuilt at once instead of being fixed for each key.
+func (c *Cache[K, V]) DeleteMany(keys []K) {
+	if len(keys) < c.queue.Len()/2 {
+		for _, key := range keys {
+			c.Delete(key)
+		}
+		return
+	}
+	for _, key := range keys {
+		if e, ok := c.items[key]; ok {
+			delete(c.items, key)
+			c.cost -= e.cost
+		}
+	}
+	old := *c.queue
+	q := old[:0]
+	for _, e := range old {
+		if c.items[e.key] == e {
+			e.index = len(q)
+			q = append(q, e)
+		}
+	}
+	for i := len(q); i < len(old); i++ {
+		old[i] = nil // avoid memory leak
 	}
+	*c.queue = q
+	heap.Init(c.queue)
+}
+
+// Clear removes all items from the cache without calling the eviction callback.
+// The reference counts of the items are also discarded.
+func (c *Cache[K, V]) Clear() {
+	c.queue = newPriorityQueue[K, V](c.cap)
+	c.items = make(map[K]*entry[K, V], c.cap)
+	c.cost = 0
 }
 
 // Len returns the number of items in the cache.
 func (c *Cache[K, V]) Len() int {
 	return c.queue.Len()
 }
+
+// evict evicts the least frequently used item and reports it to the eviction callback.
+func (c *Cache[K, V]) evict() {
+	evictedEntry := heap.Pop(c.queue)
+	if evictedEntry == nil {
+		return
+	}
+	evicted := evictedEntry.(*entry[K, V])
+	delete(c.items, evicted.key)
+	c.cost -= evicted.cost
+	if c.onEvicted != nil {
+		c.onEvicted(evicted.key, evicted.val)
+	}
+}
+
+// overCost reports whether the total cost with the additional cost exceeds the max cost.
+func (c *Cache[K, V]) overCost(additional int64) bool {
+	return c.maxCost > 0 && c.cost+additional > c.maxCost
+}
@@ -1,7 +1,10 @@
 package lfu_test
 
 import (
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Code-Hex/go-generics-cache/policy/lfu"
 )
@@ -113,3 +116,269 @@ func TestZeroCap(t *testing.T) {
 		t.Error(v)
 	}
 }
+
+func TestSetOnEvicted(t *testing.T) {
+	cache := lfu.NewCache[string, int](lfu.WithCapacity(2))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, val int) {
+		evicted = append(evicted, key+"="+strconv.Itoa(val))
+	})
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Get("foo")
+	cache.Set("foo", 3) // replacing is not an eviction
+	cache.Set("baz", 4)
+	cache.Delete("foo") // deletion is not an eviction
+	got := strings.Join(evicted, ",")
+	if want := "bar=2"; want != got {
+		t.Errorf("want evicted %q, but got %q", want, got)
+	}
+}
+
+type weighted int
+
+func (w weighted) GetCost() int64 { return int64(w) }
+
+func TestMaxCost(t *testing.T) {
+	cache := lfu.NewCache[string, weighted](lfu.WithMaxCost(10))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ weighted) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("foo", 4)
+	cache.Set("bar", 4)
+	cache.Set("baz", 4) // over the max cost
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+
+	// never stored because it exceeds the max cost by itself.
+	cache.Set("huge", 11)
+	if _, ok := cache.Get("huge"); ok {
+		t.Fatalf("want huge is rejected")
+	}
+
+	// replacing with a heavier value evicts the other items.
+	cache.Set("bar", 10)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got, ok := cache.Get("bar"); got != 10 || !ok {
+		t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+	}
+
+	got := strings.Join(evicted, ",")
+	if want := "foo,huge,baz"; want != got {
+		t.Errorf("want evicted %q, but got %q", want, got)
+	}
+}
+
+func TestSetNowFunc(t *testing.T) {
+	now := time.Now()
+	cache := lfu.NewCache[string, int](lfu.WithCapacity(2))
+	cache.SetNowFunc(func() time.Time { return now })
+
+	cache.Set("foo", 1)
+	now = now.Add(-time.Minute)
+	cache.Set("bar", 2) // referenced earlier than "foo"
+	now = now.Add(time.Hour)
+	cache.Set("baz", 3)
+
+	if _, ok := cache.Get("bar"); ok {
+		t.Errorf("want bar is evicted because it is referenced at the earliest time")
+	}
+	if _, ok := cache.Get("foo"); !ok {
+		t.Errorf("want foo is not evicted")
+	}
+}
+
+func TestPeek(t *testing.T) {
+	if _, ok := lfu.NewCache[string, int]().Peek("foo"); ok {
+		t.Fatalf("want foo is not found")
+	}
+
+	cases := []struct {
+		name   string
+		access func(c *lfu.Cache[string, int], key string) (int, bool)
+		want   string
+	}{
+		{
+			// getting "foo" makes it referenced more than "bar".
+			name:   "get",
+			access: (*lfu.Cache[string, int]).Get,
+			want:   "bar",
+		},
+		{
+			// peeking "foo" does not increase the reference count.
+			name:   "peek",
+			access: (*lfu.Cache[string, int]).Peek,
+			want:   "foo",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cache := lfu.NewCache[string, int](lfu.WithCapacity(2))
+			var evicted []string
+			cache.SetOnEvicted(func(key string, _ int) {
+				evicted = append(evicted, key)
+			})
+			cache.Set("foo", 1)
+			cache.Set("bar", 2)
+			cache.Get("bar")
+			for i := 0; i < 3; i++ {
+				if got, ok := tc.access(cache, "foo"); got != 1 || !ok {
+					t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+				}
+			}
+			cache.Set("baz", 3)
+			if got := strings.Join(evicted, ","); got != tc.want {
+				t.Errorf("want evicted %q, but got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSetMany(t *testing.T) {
+	cache := lfu.NewCache[string, int](lfu.WithCapacity(4))
+	cache.Set("foo", 1)
+	cache.Get("foo")
+	cache.Get("foo")
+	cache.SetMany(map[string]int{"foo": 2, "bar": 3, "baz": 4})
+	if got := cache.Len(); got != 3 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got, ok := cache.Peek("foo"); got != 2 || !ok {
+		t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+	}
+
+	// over the capacity.
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+	cache.Get("baz")
+	cache.SetMany(map[string]int{"qux": 5, "quux": 6})
+	if got := cache.Len(); got != 4 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := strings.Join(evicted, ","); got != "bar" {
+		t.Errorf("want evicted %q, but got %q", "bar", got)
+	}
+}
+
+func TestDeleteMany(t *testing.T) {
+	cache := lfu.NewCache[string, int](lfu.WithCapacity(4))
+	for i, key := range []string{"foo", "bar", "baz", "qux"} {
+		cache.Set(key, i)
+		for j := 0; j < i; j++ {
+			cache.Get(key)
+		}
+	}
+	cache.DeleteMany([]string{"bar", "qux", "unknown"})
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if _, ok := cache.Peek("bar"); ok {
+		t.Fatalf("want bar is deleted")
+	}
+
+	// the priority queue is still valid.
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("a", 10)
+	cache.Set("b", 11)
+	cache.Set("c", 12)
+	if got := strings.Join(evicted, ","); got != "foo" {
+		t.Errorf("want evicted %q, but got %q", "foo", got)
+	}
+}
+
+func TestResize(t *testing.T) {
+	cache := lfu.NewCache[string, int](lfu.WithCapacity(3))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Set("baz", 3)
+	cache.Get("bar")
+	cache.Get("baz")
+	cache.Get("baz")
+	if got := cache.Resize(1); got != 2 {
+		t.Fatalf("want 2 evicted items, but got %d", got)
+	}
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := strings.Join(evicted, ","); got != "foo,bar" {
+		t.Errorf("want evicted %q, but got %q", "foo,bar", got)
+	}
+
+	// growing keeps the items.
+	if got := cache.Resize(3); got != 0 {
+		t.Fatalf("want no evicted items, but got %d", got)
+	}
+	cache.Set("qux", 4)
+	cache.Set("quux", 5)
+	if got := cache.Len(); got != 3 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := len(evicted); got != 2 {
+		t.Errorf("want no more evictions, but got %v", evicted)
+	}
+}
+
+func TestResizeLessThanOne(t *testing.T) {
+	for _, cap := range []int{0, -1} {
+		cache := lfu.NewCache[string, int](lfu.WithCapacity(3))
+		cache.Set("foo", 1)
+		cache.Set("bar", 2)
+		// treated as 1.
+		if got := cache.Resize(cap); got != 1 {
+			t.Fatalf("Resize(%d): want 1 evicted item, but got %d", cap, got)
+		}
+		cache.Set("baz", 3)
+		if got := cache.Len(); got != 1 {
+			t.Fatalf("Resize(%d): invalid length: %d", cap, got)
+		}
+		if got, ok := cache.Get("baz"); got != 3 || !ok {
+			t.Fatalf("Resize(%d): invalid value got %d, cachehit %v", cap, got, ok)
+		}
+	}
+}
+
+func TestClear(t *testing.T) {
+	cache := lfu.NewCache[string, int](lfu.WithCapacity(2))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	for i := 0; i < 3; i++ {
+		cache.Get("foo")
+	}
+	cache.Clear()
+	if got := cache.Len(); got != 0 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := cache.Keys(); len(got) != 0 {
+		t.Fatalf("want no keys, but got %v", got)
+	}
+	if len(evicted) != 0 {
+		t.Fatalf("want clearing is not an eviction, but got %v", evicted)
+	}
+
+	// the reference counts are also dropped, so "foo" is now
+	// referenced less than "bar" and is evicted.
+	cache.Set("foo", 3)
+	cache.Set("bar", 4)
+	cache.Get("bar")
+	cache.Set("baz", 5)
+	if got := strings.Join(evicted, ","); got != "foo" {
+		t.Errorf("want evicted %q, but got %q", "foo", got)
+	}
+}
@@ -13,21 +13,22 @@ type entry[K comparable, V any] struct {
 	val            V
 	referenceCount int
 	referencedAt   time.Time
+	cost           int64
 }
 
-func newEntry[K comparable, V any](key K, val V) *entry[K, V] {
+func newEntry[K comparable, V any](key K, val V, now time.Time) *entry[K, V] {
 	return &entry[K, V]{
 		index:          0,
 		key:            key,
 		val:            val,
 		referenceCount: policyutil.GetReferenceCount(val),
-		referencedAt:   time.Now(),
+		referencedAt:   now,
 	}
 }
 
-func (e *entry[K, V]) referenced() {
+func (e *entry[K, V]) referenced(now time.Time) {
 	e.referenceCount++
-	e.referencedAt = time.Now()
+	e.referencedAt = now
 }
 
 type priorityQueue[K comparable, V any] []*entry[K, V]
@@ -78,8 +79,8 @@ func (q *priorityQueue[K, V]) Pop() interface{} {
 	return entry
 }
 
-func (q *priorityQueue[K, V]) update(e *entry[K, V], val V) {
+func (q *priorityQueue[K, V]) update(e *entry[K, V], val V, now time.Time) {
 	e.val = val
-	e.referenced()
+	e.referenced(now)
 	heap.Fix(q, e.index)
 }
@@ -14,7 +14,7 @@ func TestPriorityQueue(t *testing.T) {
 	entries := make([]*entry[int, int], 0, len(nums))
 
 	for _, v := range nums {
-		entry := newEntry(v, v)
+		entry := newEntry(v, v, time.Now())
 		entries = append(entries, entry)
 		heap.Push(queue, entry)
 	}
@@ -44,7 +44,7 @@ func TestPriorityQueue(t *testing.T) {
 	// - The first element is the oldest referenced_at in reference counter is 2
 	for i := 0; i < len(nums)-1; i++ {
 		entry := entries[i]
-		queue.update(entry, nums[i])
+		queue.update(entry, nums[i], time.Now())
 		time.Sleep(time.Millisecond)
 	}
 
@@ -124,7 +124,7 @@ func TestPriorityQueue_Pop(t *testing.T) {
 
 	t.Run("Pop from queue with single element", func(t *testing.T) {
 		pq := newPriorityQueue[int, string](10)
-		heap.Push(pq, newEntry(1, "one"))
+		heap.Push(pq, newEntry(1, "one", time.Now()))
 		if pq.Len() != 1 {
 			t.Fatalf("Expected queue length of 1, got %d", pq.Len())
 		}
@@ -139,9 +139,9 @@ func TestPriorityQueue_Pop(t *testing.T) {
 
 	t.Run("Pop from queue with multiple elements", func(t *testing.T) {
 		pq := newPriorityQueue[int, string](10)
-		heap.Push(pq, newEntry(1, "one"))
-		heap.Push(pq, newEntry(2, "two"))
-		heap.Push(pq, newEntry(3, "three"))
+		heap.Push(pq, newEntry(1, "one", time.Now()))
+		heap.Push(pq, newEntry(2, "two", time.Now()))
+		heap.Push(pq, newEntry(3, "three", time.Now()))
 
 		// Pop the first element
 		elem := heap.Pop(pq).(*entry[int, string])
@@ -2,6 +2,8 @@ package lru
 
 import (
 	"container/list"
+
+	"github.com/Code-Hex/go-generics-cache/policy/internal/policyutil"
 )
 
 // Cache is used a LRU (Least recently used) cache replacement policy.
@@ -10,14 +12,19 @@ import (
 // keeping track of what was used when, which is expensive if one wants
 // to make sure the algorithm always discards the least recently used item.
 type Cache[K comparable, V any] struct {
-	cap   int
-	list  *list.List
-	items map[K]*list.Element
+	cap     int
+	maxCost int64
+	cost    int64
+	list    *list.List
+	items   map[K]*list.Element
+
+	onEvicted func(key K, val V)
 }
 
 type entry[K comparable, V any] struct {
-	key K
-	val V
+	key  K
+	val  V
+	cost int64
 }
 
 // Option is an option for LRU cache.
@@ -25,6 +32,7 @@ type Option func(*options)
 
 type options struct {
 	capacity int
+	maxCost  int64
 }
 
 func newOptions() *options {
@@ -40,6 +48,20 @@ func WithCapacity(cap int) Option {
 	}
 }
 
+// WithMaxCost is an option to set the maximum total cost of items in the cache.
+// Items are evicted until the total cost is within maxCost in addition to the capacity.
+//
+// The cost of an item is the value of GetCost() method if the value satisfies
+// "interface{ GetCost() int64 }", otherwise 1. An item whose cost is greater
+// than maxCost is never stored.
+//
+// The default is 0, which means the total cost is not limited.
+func WithMaxCost(maxCost int64) Option {
+	return func(o *options) {
+		o.maxCost = maxCost
+	}
+}
+
 // NewCache creates a new non-thread safe LRU cache whose capacity is the default size (128).
 func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
 	o := newOptions()
@@ -47,9 +69,10 @@ func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
 		optFunc(o)
 	}
 	return &Cache[K, V]{
-		cap:   o.capacity,
-		list:  list.New(),
-		items: make(map[K]*list.Element, o.capacity),
+		cap:     o.capacity,
+		maxCost: o.maxCost,
+		list:    list.New(),
+		items:   make(map[K]*list.Element, o.capacity),
 	}
 }
 
@@ -64,26 +87,74 @@ func (c *Cache[K, V]) Get(key K) (zero V, _ bool) {
 	return e.Value.(*entry[K, V]).val, true
 }
 
+// Peek looks up a key's value from the cache without updating
+// the cache order.
+func (c *Cache[K, V]) Peek(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	return e.Value.(*entry[K, V]).val, true
+}
+
 // Set sets a value to the cache with key. replacing any existing value.
+//
+// If the cache is created with WithMaxCost option, the least recently used
+// items are evicted until the total cost is within the max cost.
 func (c *Cache[K, V]) Set(key K, val V) {
+	cost := policyutil.GetCost(val)
+	if c.maxCost > 0 && cost > c.maxCost {
+		// the item never fits in the cache.
+		c.Delete(key)
+		if c.onEvicted != nil {
+			c.onEvicted(key, val)
+		}
+		return
+	}
+
 	if e, ok := c.items[key]; ok {
 		// updates cache order
 		c.list.MoveToFront(e)
 		entry := e.Value.(*entry[K, V])
+		c.cost += cost - entry.cost
 		entry.val = val
-		return
+		entry.cost = cost
+	} else {
+		newEntry := &entry[K, V]{
+			key:  key,
+			val:  val,
+			cost: cost,
+		}
+		e := c.list.PushFront(newEntry)
+		c.items[key] = e
+		c.cost += cost
 	}
 
-	newEntry := &entry[K, V]{
-		key: key,
-		val: val,
+	for c.list.Len() > 0 && (c.list.Len() > c.cap || c.overCost(0)) {
+		c.deleteOldest()
 	}
-	e := c.list.PushFront(newEntry)
-	c.items[key] = e
+}
 
-	if c.list.Len() > c.cap {
+// Resize changes the capacity of the cache. If the number of items exceeds
+// the new capacity, the least recently used items are evicted and reported
+// to the eviction callback. It returns the number of evicted items.
+// If cap is less than 1, it is treated as 1.
+func (c *Cache[K, V]) Resize(cap int) (evicted int) {
+	if cap < 1 {
+		cap = 1
+	}
+	c.cap = cap
+	for c.list.Len() > 0 && c.list.Len() > cap {
 		c.deleteOldest()
+		evicted++
 	}
+	return evicted
+}
+
+// SetOnEvicted sets a callback which is called with the entry evicted
+// by the replacement policy when the cache is over the capacity.
+func (c *Cache[K, V]) SetOnEvicted(f func(key K, val V)) {
+	c.onEvicted = f
 }
 
 // Keys returns the keys of the cache. the order is from oldest to newest.
@@ -96,6 +167,13 @@ func (c *Cache[K, V]) Keys() []K {
 	return keys
 }
 
+// Clear removes all items from the cache without calling the eviction callback.
+func (c *Cache[K, V]) Clear() {
+	c.list.Init()
+	c.items = make(map[K]*list.Element, c.cap)
+	c.cost = 0
+}
+
 // Len returns the number of items in the cache.
 func (c *Cache[K, V]) Len() int {
 	return c.list.Len()
@@ -111,10 +189,20 @@ func (c *Cache[K, V]) Delete(key K) {
 func (c *Cache[K, V]) deleteOldest() {
 	e := c.list.Back()
 	c.delete(e)
+	if c.onEvicted != nil {
+		entry := e.Value.(*entry[K, V])
+		c.onEvicted(entry.key, entry.val)
+	}
 }
 
 func (c *Cache[K, V]) delete(e *list.Element) {
 	c.list.Remove(e)
 	entry := e.Value.(*entry[K, V])
 	delete(c.items, entry.key)
+	c.cost -= entry.cost
+}
+
+// overCost reports whether the total cost with the additional cost exceeds the max cost.
+func (c *Cache[K, V]) overCost(additional int64) bool {
+	return c.maxCost > 0 && c.cost+additional > c.maxCost
 }
@@ -1,6 +1,8 @@
 package lru_test
 
 import (
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/Code-Hex/go-generics-cache/policy/lru"
@@ -63,3 +65,206 @@ func TestDelete(t *testing.T) {
 		t.Fatalf("invalid get after deleted %v", ok)
 	}
 }
+
+func TestNegativeCap(t *testing.T) {
+	cache := lru.NewCache[string, int](lru.WithCapacity(-1))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+	// the item never fits in the cache.
+	cache.Set("foo", 1)
+	if got := cache.Len(); got != 0 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := strings.Join(evicted, ","); got != "foo" {
+		t.Errorf("want evicted %q, but got %q", "foo", got)
+	}
+}
+
+func TestSetOnEvicted(t *testing.T) {
+	cache := lru.NewCache[string, int](lru.WithCapacity(2))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, val int) {
+		evicted = append(evicted, key+"="+strconv.Itoa(val))
+	})
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Get("foo")
+	cache.Set("foo", 3) // replacing is not an eviction
+	cache.Set("baz", 4)
+	cache.Delete("foo") // deletion is not an eviction
+	got := strings.Join(evicted, ",")
+	if want := "bar=2"; want != got {
+		t.Errorf("want evicted %q, but got %q", want, got)
+	}
+}
+
+type weighted int
+
+func (w weighted) GetCost() int64 { return int64(w) }
+
+func TestMaxCost(t *testing.T) {
+	cache := lru.NewCache[string, weighted](lru.WithMaxCost(10))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ weighted) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("foo", 4)
+	cache.Set("bar", 4)
+	cache.Set("baz", 4) // over the max cost
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+
+	// never stored because it exceeds the max cost by itself.
+	cache.Set("huge", 11)
+	if _, ok := cache.Get("huge"); ok {
+		t.Fatalf("want huge is rejected")
+	}
+
+	// replacing with a heavier value evicts the other items.
+	cache.Set("bar", 10)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got, ok := cache.Get("bar"); got != 10 || !ok {
+		t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+	}
+
+	got := strings.Join(evicted, ",")
+	if want := "foo,huge,baz"; want != got {
+		t.Errorf("want evicted %q, but got %q", want, got)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	if _, ok := lru.NewCache[string, int]().Peek("foo"); ok {
+		t.Fatalf("want foo is not found")
+	}
+
+	cases := []struct {
+		name   string
+		access func(c *lru.Cache[string, int], key string) (int, bool)
+		want   string
+	}{
+		{
+			// getting "foo" makes it the most recently used.
+			name:   "get",
+			access: (*lru.Cache[string, int]).Get,
+			want:   "bar",
+		},
+		{
+			// peeking "foo" leaves it the least recently used.
+			name:   "peek",
+			access: (*lru.Cache[string, int]).Peek,
+			want:   "foo",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cache := lru.NewCache[string, int](lru.WithCapacity(2))
+			var evicted []string
+			cache.SetOnEvicted(func(key string, _ int) {
+				evicted = append(evicted, key)
+			})
+			cache.Set("foo", 1)
+			cache.Set("bar", 2)
+			for i := 0; i < 3; i++ {
+				if got, ok := tc.access(cache, "foo"); got != 1 || !ok {
+					t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+				}
+			}
+			cache.Set("baz", 3)
+			if got := strings.Join(evicted, ","); got != tc.want {
+				t.Errorf("want evicted %q, but got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestResize(t *testing.T) {
+	cache := lru.NewCache[string, int](lru.WithCapacity(3))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Set("baz", 3)
+	if got := cache.Resize(1); got != 2 {
+		t.Fatalf("want 2 evicted items, but got %d", got)
+	}
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := strings.Join(evicted, ","); got != "foo,bar" {
+		t.Errorf("want evicted %q, but got %q", "foo,bar", got)
+	}
+
+	// growing keeps the items.
+	if got := cache.Resize(3); got != 0 {
+		t.Fatalf("want no evicted items, but got %d", got)
+	}
+	cache.Set("qux", 4)
+	cache.Set("quux", 5)
+	if got := cache.Len(); got != 3 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := len(evicted); got != 2 {
+		t.Errorf("want no more evictions, but got %v", evicted)
+	}
+}
+
+func TestResizeLessThanOne(t *testing.T) {
+	for _, cap := range []int{0, -1} {
+		cache := lru.NewCache[string, int](lru.WithCapacity(3))
+		cache.Set("foo", 1)
+		cache.Set("bar", 2)
+		// treated as 1.
+		if got := cache.Resize(cap); got != 1 {
+			t.Fatalf("Resize(%d): want 1 evicted item, but got %d", cap, got)
+		}
+		cache.Set("baz", 3)
+		if got := cache.Len(); got != 1 {
+			t.Fatalf("Resize(%d): invalid length: %d", cap, got)
+		}
+		if got, ok := cache.Get("baz"); got != 3 || !ok {
+			t.Fatalf("Resize(%d): invalid value got %d, cachehit %v", cap, got, ok)
+		}
+	}
+}
+
+func TestClear(t *testing.T) {
+	cache := lru.NewCache[string, int](lru.WithCapacity(10), lru.WithMaxCost(2))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Get("foo")
+	cache.Clear()
+	if got := cache.Len(); got != 0 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := cache.Keys(); len(got) != 0 {
+		t.Fatalf("want no keys, but got %v", got)
+	}
+	if len(evicted) != 0 {
+		t.Fatalf("want clearing is not an eviction, but got %v", evicted)
+	}
+
+	// the total cost is also reset, so two items fit without eviction.
+	cache.Set("baz", 3)
+	cache.Set("qux", 4)
+	if len(evicted) != 0 {
+		t.Fatalf("want no evictions, but got %v", evicted)
+	}
+
+	// the order before clearing is dropped, so "baz" is the least recently used.
+	cache.Set("quux", 5)
+	if got := strings.Join(evicted, ","); got != "baz" {
+		t.Errorf("want evicted %q, but got %q", "baz", got)
+	}
+}
@@ -60,6 +60,16 @@ func ExampleCache_Len() {
 	// 3
 }
 
+func ExampleCache_Clear() {
+	c := simple.NewCache[string, int]()
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Clear()
+	fmt.Println(c.Len(), c.Keys())
+	// Output:
+	// 0 []
+}
+
 func BenchmarkLenWithKeys(b *testing.B) {
 	c := simple.NewCache[string, int]()
 	c.Set("foo", 1)
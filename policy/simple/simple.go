@@ -41,6 +41,16 @@ func (c *Cache[K, V]) Get(k K) (val V, ok bool) {
 	return got.val, true
 }
 
+// Peek gets an item from the cache. It is the same as Get
+// because the simple cache has no eviction order.
+func (c *Cache[K, V]) Peek(k K) (val V, ok bool) {
+	got, found := c.items[k]
+	if !found {
+		return
+	}
+	return got.val, true
+}
+
 // Keys returns cache keys. the order is sorted by created.
 func (c *Cache[K, _]) Keys() []K {
 	ret := make([]K, 0, len(c.items))
@@ -60,6 +70,11 @@ func (c *Cache[K, V]) Delete(key K) {
 	delete(c.items, key)
 }
 
+// Clear removes all items from the cache.
+func (c *Cache[K, V]) Clear() {
+	c.items = make(map[K]*entry[V], 0)
+}
+
 // Len returns the number of items in the cache.
 func (c *Cache[K, V]) Len() int {
 	return len(c.items)
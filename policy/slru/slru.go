@@ -0,0 +1,229 @@
+package slru
+
+import (
+	"container/list"
+)
+
+// Cache is used a SLRU (Segmented LRU) cache replacement policy.
+//
+// SLRU divides the cache into two LRU segments, a probationary segment and a
+// protected segment. New items are placed in the probationary segment, and
+// promoted to the protected segment when they are accessed again. When the
+// protected segment is full, the least recently used item in it is demoted
+// back to the most recently used position of the probationary segment, so
+// that the items get another chance before eviction. Items are evicted from
+// the probationary segment, therefore one-time scans do not flush the items
+// in the protected segment.
+type Cache[K comparable, V any] struct {
+	cap          int
+	protectedCap int
+	ratio        float64
+
+	probation *list.List
+	protected *list.List
+	items     map[K]*list.Element
+
+	onEvicted func(key K, val V)
+}
+
+type entry[K comparable, V any] struct {
+	key K
+	val V
+	// list is the segment which the entry belongs to.
+	list *list.List
+}
+
+// Option is an option for SLRU cache.
+type Option func(*options)
+
+type options struct {
+	capacity       int
+	protectedRatio float64
+}
+
+func newOptions() *options {
+	return &options{
+		capacity:       128,
+		protectedRatio: 0.8,
+	}
+}
+
+// WithCapacity is an option to set cache capacity.
+func WithCapacity(cap int) Option {
+	return func(o *options) {
+		o.capacity = cap
+	}
+}
+
+// WithProtectedRatio is an option to set the ratio of the capacity which is
+// used for the protected segment. The rest is used for the probationary segment.
+// The ratio must be between 0 and 1, otherwise it is ignored.
+//
+// The default is 0.8.
+func WithProtectedRatio(ratio float64) Option {
+	return func(o *options) {
+		if ratio >= 0 && ratio <= 1 {
+			o.protectedRatio = ratio
+		}
+	}
+}
+
+// NewCache creates a new non-thread safe SLRU cache whose capacity is the default size (128).
+func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
+	o := newOptions()
+	for _, optFunc := range opts {
+		optFunc(o)
+	}
+	return &Cache[K, V]{
+		cap:          o.capacity,
+		protectedCap: protectedCap(o.capacity, o.protectedRatio),
+		ratio:        o.protectedRatio,
+		probation:    list.New(),
+		protected:    list.New(),
+		items:        make(map[K]*list.Element, o.capacity),
+	}
+}
+
+func protectedCap(cap int, ratio float64) int {
+	return int(float64(cap) * ratio)
+}
+
+// Get looks up a key's value from the cache.
+// The item in the probationary segment is promoted to the protected segment.
+func (c *Cache[K, V]) Get(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	c.access(e)
+	return e.Value.(*entry[K, V]).val, true
+}
+
+// Peek looks up a key's value from the cache without updating the cache order.
+func (c *Cache[K, V]) Peek(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	return e.Value.(*entry[K, V]).val, true
+}
+
+// Set sets a value to the cache with key. replacing any existing value.
+//
+// Replacing the value is treated as an access to the item. A new item is placed
+// in the probationary segment, and the least recently used item in the
+// probationary segment is evicted if the cache is over the capacity.
+func (c *Cache[K, V]) Set(key K, val V) {
+	if e, ok := c.items[key]; ok {
+		e.Value.(*entry[K, V]).val = val
+		c.access(e)
+		return
+	}
+	c.items[key] = c.probation.PushFront(&entry[K, V]{
+		key:  key,
+		val:  val,
+		list: c.probation,
+	})
+	for len(c.items) > c.cap && len(c.items) > 0 {
+		c.evict()
+	}
+}
+
+// SetOnEvicted sets a callback which is called with the entry evicted
+// by the replacement policy when the cache is over the capacity.
+func (c *Cache[K, V]) SetOnEvicted(f func(key K, val V)) {
+	c.onEvicted = f
+}
+
+// Keys returns the keys of the cache. the order is from oldest to newest in
+// the probationary segment, followed by from oldest to newest in the protected segment.
+func (c *Cache[K, V]) Keys() []K {
+	keys := make([]K, 0, len(c.items))
+	for _, l := range []*list.List{c.probation, c.protected} {
+		for e := l.Back(); e != nil; e = e.Prev() {
+			keys = append(keys, e.Value.(*entry[K, V]).key)
+		}
+	}
+	return keys
+}
+
+// Delete deletes the item with provided key from the cache.
+func (c *Cache[K, V]) Delete(key K) {
+	if e, ok := c.items[key]; ok {
+		e.Value.(*entry[K, V]).list.Remove(e)
+		delete(c.items, key)
+	}
+}
+
+// Clear removes all items from the cache without calling the eviction callback.
+func (c *Cache[K, V]) Clear() {
+	c.probation.Init()
+	c.protected.Init()
+	c.items = make(map[K]*list.Element, c.cap)
+}
+
+// Len returns the number of items in the cache.
+func (c *Cache[K, V]) Len() int {
+	return len(c.items)
+}
+
+// Resize changes the capacity of the cache. The capacity of the protected
+// segment is also changed by the ratio. If the number of items exceeds the
+// new capacity, items are evicted from the probationary segment and reported
+// to the eviction callback. It returns the number of evicted items.
+// If cap is less than 1, it is treated as 1.
+func (c *Cache[K, V]) Resize(cap int) (evicted int) {
+	if cap < 1 {
+		cap = 1
+	}
+	c.cap = cap
+	c.protectedCap = protectedCap(cap, c.ratio)
+	for c.protected.Len() > c.protectedCap {
+		c.demote()
+	}
+	for len(c.items) > cap && len(c.items) > 0 {
+		c.evict()
+		evicted++
+	}
+	return evicted
+}
+
+// access records an access to the item, promoting it to the protected segment.
+func (c *Cache[K, V]) access(e *list.Element) {
+	ent := e.Value.(*entry[K, V])
+	if ent.list == c.protected {
+		c.protected.MoveToFront(e)
+		return
+	}
+	c.probation.Remove(e)
+	ent.list = c.protected
+	c.items[ent.key] = c.protected.PushFront(ent)
+	for c.protected.Len() > c.protectedCap {
+		c.demote()
+	}
+}
+
+// demote moves the least recently used item in the protected segment to
+// the most recently used position of the probationary segment.
+func (c *Cache[K, V]) demote() {
+	e := c.protected.Back()
+	ent := e.Value.(*entry[K, V])
+	c.protected.Remove(e)
+	ent.list = c.probation
+	c.items[ent.key] = c.probation.PushFront(ent)
+}
+
+// evict evicts the least recently used item in the probationary segment, or
+// in the protected segment if the probationary segment is empty.
+func (c *Cache[K, V]) evict() {
+	e := c.probation.Back()
+	if e == nil {
+		e = c.protected.Back()
+	}
+	ent := e.Value.(*entry[K, V])
+	ent.list.Remove(e)
+	delete(c.items, ent.key)
+	if c.onEvicted != nil {
+		c.onEvicted(ent.key, ent.val)
+	}
+}
@@ -0,0 +1,299 @@
+package slru_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Code-Hex/go-generics-cache/policy/slru"
+)
+
+func TestSet(t *testing.T) {
+	// set capacity is 1
+	cache := slru.NewCache[string, int](slru.WithCapacity(1))
+	cache.Set("foo", 1)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got, ok := cache.Get("foo"); got != 1 || !ok {
+		t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+	}
+
+	// if over the cap
+	cache.Set("bar", 2)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	bar, ok := cache.Get("bar")
+	if bar != 2 || !ok {
+		t.Fatalf("invalid value bar %d, cachehit %v", bar, ok)
+	}
+
+	// checks deleted oldest
+	if _, ok := cache.Get("foo"); ok {
+		t.Fatalf("invalid delete oldest value foo %v", ok)
+	}
+
+	// valid: if over the cap but same key
+	cache.Set("bar", 100)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	bar, ok = cache.Get("bar")
+	if bar != 100 || !ok {
+		t.Fatalf("invalid replacing value bar %d, cachehit %v", bar, ok)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cache := slru.NewCache[string, int](slru.WithCapacity(2))
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Get("bar") // promotes to the protected segment
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+
+	cache.Delete("foo2")
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length after deleted does not exist key: %d", got)
+	}
+
+	for _, key := range []string{"foo", "bar"} {
+		cache.Delete(key)
+		if _, ok := cache.Get(key); ok {
+			t.Fatalf("invalid get after deleted %q %v", key, ok)
+		}
+	}
+	if got := cache.Len(); got != 0 {
+		t.Fatalf("invalid length after deleted: %d", got)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	cache := slru.NewCache[string, int]()
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Set("baz", 3)
+	cache.Get("bar") // promotes to the protected segment
+	cache.Set("foo", 4)
+
+	got := strings.Join(cache.Keys(), ",")
+	want := strings.Join([]string{
+		"baz", // probation
+		"bar", // protected
+		"foo",
+	}, ",")
+	if got != want {
+		t.Errorf("want %q, but got %q", want, got)
+	}
+	if len(cache.Keys()) != cache.Len() {
+		t.Errorf("want number of keys %d, but got %d", len(cache.Keys()), cache.Len())
+	}
+}
+
+func TestDemote(t *testing.T) {
+	// the protected segment can hold 2 items.
+	cache := slru.NewCache[string, int](
+		slru.WithCapacity(4),
+		slru.WithProtectedRatio(0.5),
+	)
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+	for _, key := range []string{"foo", "bar", "baz"} {
+		cache.Set(key, 0)
+	}
+	for _, key := range []string{"foo", "bar", "baz"} {
+		cache.Get(key)
+	}
+
+	// "foo" is demoted from the protected segment to the probationary segment.
+	got := strings.Join(cache.Keys(), ",")
+	if want := "foo,bar,baz"; want != got {
+		t.Fatalf("want %q, but got %q", want, got)
+	}
+
+	// the demoted item is evicted first from the probationary segment.
+	cache.Set("qux", 1)
+	cache.Set("quux", 2)
+	if got := strings.Join(evicted, ","); got != "foo" {
+		t.Errorf("want evicted %q, but got %q", "foo", got)
+	}
+	if got := cache.Len(); got != 4 {
+		t.Errorf("invalid length: %d", got)
+	}
+}
+
+func TestScanResistance(t *testing.T) {
+	cache := slru.NewCache[string, int](slru.WithCapacity(4))
+	// hot items are used frequently.
+	for _, key := range []string{"hot1", "hot2"} {
+		cache.Set(key, 0)
+		cache.Get(key)
+	}
+
+	// one-time scan must not flush frequently used items.
+	for i := 0; i < 100; i++ {
+		cache.Set(strconv.Itoa(i), i)
+	}
+
+	for _, key := range []string{"hot1", "hot2"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("want %q is not evicted by scan", key)
+		}
+	}
+	if got := cache.Len(); got != 4 {
+		t.Errorf("invalid length: %d", got)
+	}
+}
+
+func TestSetOnEvicted(t *testing.T) {
+	cache := slru.NewCache[string, int](slru.WithCapacity(2))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, val int) {
+		evicted = append(evicted, key+"="+strconv.Itoa(val))
+	})
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Get("foo")
+	cache.Set("foo", 3) // replacing is not an eviction
+	cache.Set("baz", 4)
+	cache.Delete("foo") // deletion is not an eviction
+
+	got := strings.Join(evicted, ",")
+	if want := "bar=2"; want != got {
+		t.Errorf("want evicted %q, but got %q", want, got)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	if _, ok := slru.NewCache[string, int]().Peek("foo"); ok {
+		t.Fatalf("want foo is not found")
+	}
+
+	cases := []struct {
+		name   string
+		access func(c *slru.Cache[string, int], key string) (int, bool)
+		want   string
+	}{
+		{
+			// getting "foo" promotes it to the protected segment.
+			name:   "get",
+			access: (*slru.Cache[string, int]).Get,
+			want:   "bar",
+		},
+		{
+			// peeking "foo" leaves it in the probationary segment.
+			name:   "peek",
+			access: (*slru.Cache[string, int]).Peek,
+			want:   "foo",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cache := slru.NewCache[string, int](slru.WithCapacity(2))
+			var evicted []string
+			cache.SetOnEvicted(func(key string, _ int) {
+				evicted = append(evicted, key)
+			})
+			cache.Set("foo", 1)
+			cache.Set("bar", 2)
+			for i := 0; i < 3; i++ {
+				if got, ok := tc.access(cache, "foo"); got != 1 || !ok {
+					t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+				}
+			}
+			cache.Set("baz", 3)
+			if got := strings.Join(evicted, ","); got != tc.want {
+				t.Errorf("want evicted %q, but got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestResize(t *testing.T) {
+	cache := slru.NewCache[string, int](slru.WithCapacity(3))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Set("baz", 3)
+	cache.Get("foo") // promotes to the protected segment
+
+	// "foo" is demoted and survives as the newest in the probationary segment.
+	if got := cache.Resize(1); got != 2 {
+		t.Fatalf("want 2 evicted items, but got %d", got)
+	}
+	if got := strings.Join(cache.Keys(), ","); got != "foo" {
+		t.Fatalf("want keys %q, but got %q", "foo", got)
+	}
+	if got := strings.Join(evicted, ","); got != "bar,baz" {
+		t.Errorf("want evicted %q, but got %q", "bar,baz", got)
+	}
+
+	// growing keeps the items.
+	if got := cache.Resize(3); got != 0 {
+		t.Fatalf("want no evicted items, but got %d", got)
+	}
+	cache.Set("qux", 4)
+	cache.Set("quux", 5)
+	if got := cache.Len(); got != 3 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := len(evicted); got != 2 {
+		t.Errorf("want no more evictions, but got %v", evicted)
+	}
+}
+
+func TestResizeLessThanOne(t *testing.T) {
+	for _, cap := range []int{0, -1} {
+		cache := slru.NewCache[string, int](slru.WithCapacity(3))
+		cache.Set("foo", 1)
+		cache.Set("bar", 2)
+		// treated as 1.
+		if got := cache.Resize(cap); got != 1 {
+			t.Fatalf("Resize(%d): want 1 evicted item, but got %d", cap, got)
+		}
+		cache.Set("baz", 3)
+		if got := cache.Len(); got != 1 {
+			t.Fatalf("Resize(%d): invalid length: %d", cap, got)
+		}
+		if got, ok := cache.Get("baz"); got != 3 || !ok {
+			t.Fatalf("Resize(%d): invalid value got %d, cachehit %v", cap, got, ok)
+		}
+	}
+}
+
+func TestClear(t *testing.T) {
+	cache := slru.NewCache[string, int](slru.WithCapacity(2))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Get("foo") // "foo" is promoted to the protected segment
+	cache.Clear()
+	if got := cache.Len(); got != 0 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := cache.Keys(); len(got) != 0 {
+		t.Fatalf("want no keys, but got %v", got)
+	}
+	if len(evicted) != 0 {
+		t.Fatalf("want clearing is not an eviction, but got %v", evicted)
+	}
+
+	// the protected segment is also cleared, so "foo" is inserted to the
+	// probationary segment and evicted as the least recently used item.
+	cache.Set("foo", 3)
+	cache.Set("bar", 4)
+	cache.Set("baz", 5)
+	if got := strings.Join(evicted, ","); got != "foo" {
+		t.Errorf("want evicted %q, but got %q", "foo", got)
+	}
+}
@@ -0,0 +1,23 @@
+package tinylfu_test
+
+import (
+	"fmt"
+
+	"github.com/Code-Hex/go-generics-cache/policy/tinylfu"
+)
+
+func ExampleNewCache() {
+	c := tinylfu.NewCache[string, int]()
+	c.Set("a", 1)
+	c.Set("b", 2)
+	av, aok := c.Get("a")
+	bv, bok := c.Get("b")
+	cv, cok := c.Get("c")
+	fmt.Println(av, aok)
+	fmt.Println(bv, bok)
+	fmt.Println(cv, cok)
+	// Output:
+	// 1 true
+	// 2 true
+	// 0 false
+}
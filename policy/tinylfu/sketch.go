@@ -0,0 +1,185 @@
+package tinylfu
+
+// sketchDepth is the number of rows of the Count-Min sketch.
+const sketchDepth = 4
+
+// maxCount is the maximum value of a counter. TinyLFU uses 4-bit counters.
+const maxCount = 15
+
+// sketchSeeds are used to derive an independent index for each row.
+var sketchSeeds = [sketchDepth]uint64{
+	0xc3a5c85c97cb3127,
+	0xb492b66fbe98f273,
+	0x9ae16a3b2f90404f,
+	0xcbf29ce484222325,
+}
+
+// countMinSketch is a Count-Min sketch which approximates the access
+// frequency of keys in a compact space.
+type countMinSketch struct {
+	rows [sketchDepth][]uint8
+	// shift is used to take the upper bits of the hash as the index.
+	shift uint
+}
+
+func newCountMinSketch(width int) *countMinSketch {
+	w := nextPowerOfTwo(width)
+	s := &countMinSketch{
+		shift: 64,
+	}
+	for n := w; n > 1; n >>= 1 {
+		s.shift--
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, w)
+	}
+	return s
+}
+
+// increment increments the counters of the hashed key.
+func (s *countMinSketch) increment(h uint64) {
+	for i := range s.rows {
+		idx := s.index(h, i)
+		if s.rows[i][idx] < maxCount {
+			s.rows[i][idx]++
+		}
+	}
+}
+
+// estimate returns the estimated access frequency of the hashed key.
+func (s *countMinSketch) estimate(h uint64) uint8 {
+	est := uint8(maxCount)
+	for i := range s.rows {
+		if c := s.rows[i][s.index(h, i)]; c < est {
+			est = c
+		}
+	}
+	return est
+}
+
+// reset halves all counters to age the frequencies.
+func (s *countMinSketch) reset() {
+	for _, row := range s.rows {
+		for i := range row {
+			row[i] >>= 1
+		}
+	}
+}
+
+func (s *countMinSketch) index(h uint64, i int) uint64 {
+	return (h * sketchSeeds[i]) >> s.shift
+}
+
+// doorkeeper is a bloom filter placed in front of the Count-Min sketch.
+// It filters out keys which are accessed only once so that they do not
+// occupy the counters.
+type doorkeeper struct {
+	bits []uint64
+	mask uint64
+}
+
+// doorkeeperHashes is the number of hash functions of the bloom filter.
+const doorkeeperHashes = 3
+
+func newDoorkeeper(size int) *doorkeeper {
+	n := nextPowerOfTwo(size)
+	if n < 64 {
+		n = 64
+	}
+	return &doorkeeper{
+		bits: make([]uint64, n/64),
+		mask: uint64(n - 1),
+	}
+}
+
+// add adds the hashed key to the filter, and reports whether the key was
+// already present.
+func (d *doorkeeper) add(h uint64) bool {
+	present := true
+	h1, h2 := h, (h>>32)|1
+	for i := uint64(0); i < doorkeeperHashes; i++ {
+		bit := (h1 + i*h2) & d.mask
+		word, mask := bit/64, uint64(1)<<(bit%64)
+		if d.bits[word]&mask == 0 {
+			present = false
+			d.bits[word] |= mask
+		}
+	}
+	return present
+}
+
+// contains reports whether the hashed key may be in the filter.
+func (d *doorkeeper) contains(h uint64) bool {
+	h1, h2 := h, (h>>32)|1
+	for i := uint64(0); i < doorkeeperHashes; i++ {
+		bit := (h1 + i*h2) & d.mask
+		if d.bits[bit/64]&(uint64(1)<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *doorkeeper) reset() {
+	for i := range d.bits {
+		d.bits[i] = 0
+	}
+}
+
+// tinyLFU is an admission policy which estimates the access frequency of keys.
+type tinyLFU struct {
+	sketch     *countMinSketch
+	doorkeeper *doorkeeper
+	// additions is the number of increments since the last reset.
+	additions int
+	// sampleSize is the number of increments which triggers the aging.
+	sampleSize int
+}
+
+func newTinyLFU(capacity int) *tinyLFU {
+	if capacity < 1 {
+		capacity = 1
+	}
+	sampleSize := capacity * 10
+	return &tinyLFU{
+		sketch:     newCountMinSketch(sampleSize),
+		doorkeeper: newDoorkeeper(sampleSize * 8),
+		sampleSize: sampleSize,
+	}
+}
+
+// increment records an access of the hashed key.
+func (t *tinyLFU) increment(h uint64) {
+	// the first access is recorded only in the doorkeeper.
+	if t.doorkeeper.add(h) {
+		t.sketch.increment(h)
+	}
+	t.additions++
+	if t.additions >= t.sampleSize {
+		t.reset()
+	}
+}
+
+// estimate returns the estimated access frequency of the hashed key.
+func (t *tinyLFU) estimate(h uint64) int {
+	n := int(t.sketch.estimate(h))
+	if t.doorkeeper.contains(h) {
+		n++
+	}
+	return n
+}
+
+// reset ages all frequencies so that the sketch follows recent access patterns.
+func (t *tinyLFU) reset() {
+	t.additions = 0
+	t.sketch.reset()
+	t.doorkeeper.reset()
+}
+
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
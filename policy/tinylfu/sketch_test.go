@@ -0,0 +1,76 @@
+package tinylfu
+
+import "testing"
+
+func TestCountMinSketch(t *testing.T) {
+	s := newCountMinSketch(16)
+	for i := 0; i < 5; i++ {
+		s.increment(1)
+	}
+	if got := s.estimate(1); got != 5 {
+		t.Errorf("want estimate %d but got %d", 5, got)
+	}
+	if got := s.estimate(2); got != 0 {
+		t.Errorf("want estimate %d but got %d", 0, got)
+	}
+
+	for i := 0; i < 100; i++ {
+		s.increment(1)
+	}
+	if got := s.estimate(1); got != maxCount {
+		t.Errorf("want estimate is saturated to %d but got %d", maxCount, got)
+	}
+
+	s.reset()
+	if got := s.estimate(1); got != maxCount/2 {
+		t.Errorf("want estimate %d after reset but got %d", maxCount/2, got)
+	}
+}
+
+func TestDoorkeeper(t *testing.T) {
+	d := newDoorkeeper(64)
+	if d.contains(1) {
+		t.Errorf("want not contained")
+	}
+	if d.add(1) {
+		t.Errorf("want not present at the first time")
+	}
+	if !d.add(1) {
+		t.Errorf("want present at the second time")
+	}
+	if !d.contains(1) {
+		t.Errorf("want contained")
+	}
+	d.reset()
+	if d.contains(1) {
+		t.Errorf("want not contained after reset")
+	}
+}
+
+func TestTinyLFU(t *testing.T) {
+	lfu := newTinyLFU(10)
+	lfu.increment(1)
+	if got := lfu.estimate(1); got != 1 {
+		t.Errorf("want the first access is recorded in the doorkeeper: %d", got)
+	}
+	lfu.increment(1)
+	if got := lfu.estimate(1); got != 2 {
+		t.Errorf("want estimate %d but got %d", 2, got)
+	}
+
+	// aging
+	lfu.reset()
+	if got := lfu.estimate(1); got != 0 {
+		t.Errorf("want estimate is aged but got %d", got)
+	}
+	for i := 0; i < lfu.sampleSize-1; i++ {
+		lfu.increment(uint64(i))
+	}
+	if lfu.additions != lfu.sampleSize-1 {
+		t.Fatalf("want additions %d but got %d", lfu.sampleSize-1, lfu.additions)
+	}
+	lfu.increment(1)
+	if lfu.additions != 0 {
+		t.Errorf("want reset when additions reach the sample size but got %d", lfu.additions)
+	}
+}
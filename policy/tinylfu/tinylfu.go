@@ -0,0 +1,322 @@
+package tinylfu
+
+import (
+	"container/list"
+
+	"github.com/Code-Hex/go-generics-cache/internal/hashutil"
+	"github.com/Code-Hex/go-generics-cache/policy/internal/policyutil"
+)
+
+// Cache is used a W-TinyLFU (Window Tiny Least Frequently Used) cache replacement policy.
+//
+// New items are placed in a small LRU window. Items evicted from the window
+// are candidates to enter the main space which is a segmented LRU composed of
+// a probation and a protected segment. The candidate is admitted only if the
+// access frequency of it is higher than the victim of the main space, which is
+// estimated by TinyLFU: a Count-Min sketch with periodic aging placed behind
+// a doorkeeper bloom filter.
+type Cache[K comparable, V any] struct {
+	hasher *hashutil.Hasher[K]
+	lfu    *tinyLFU
+
+	items map[K]*list.Element
+
+	window    *list.List
+	probation *list.List
+	protected *list.List
+
+	windowCap    int
+	protectedCap int
+	mainCap      int
+
+	maxCost int64
+	cost    int64
+
+	onEvicted func(key K, val V)
+}
+
+type segment int
+
+const (
+	windowSegment segment = iota
+	probationSegment
+	protectedSegment
+)
+
+type entry[K comparable, V any] struct {
+	key     K
+	val     V
+	hash    uint64
+	cost    int64
+	segment segment
+}
+
+// Option is an option for W-TinyLFU cache.
+type Option func(*options)
+
+type options struct {
+	capacity int
+	maxCost  int64
+}
+
+func newOptions() *options {
+	return &options{
+		capacity: 128,
+	}
+}
+
+// WithCapacity is an option to set cache capacity.
+func WithCapacity(cap int) Option {
+	return func(o *options) {
+		o.capacity = cap
+	}
+}
+
+// WithMaxCost is an option to set the maximum total cost of items in the cache.
+// Items are evicted until the total cost is within maxCost in addition to the capacity.
+//
+// The cost of an item is the value of GetCost() method if the value satisfies
+// "interface{ GetCost() int64 }", otherwise 1. An item whose cost is greater
+// than maxCost is never stored.
+//
+// The default is 0, which means the total cost is not limited.
+func WithMaxCost(maxCost int64) Option {
+	return func(o *options) {
+		o.maxCost = maxCost
+	}
+}
+
+// NewCache creates a new non-thread safe W-TinyLFU cache whose capacity is the default size (128).
+//
+// 1% of the capacity is used for the window, and the rest is used for the main
+// space. 80% of the main space is used for the protected segment.
+func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
+	o := newOptions()
+	for _, optFunc := range opts {
+		optFunc(o)
+	}
+	windowCap := o.capacity / 100
+	if windowCap < 1 {
+		windowCap = 1
+	}
+	mainCap := o.capacity - windowCap
+	if mainCap < 0 {
+		mainCap = 0
+	}
+	return &Cache[K, V]{
+		hasher:       hashutil.New[K](),
+		lfu:          newTinyLFU(o.capacity),
+		items:        make(map[K]*list.Element, o.capacity),
+		window:       list.New(),
+		probation:    list.New(),
+		protected:    list.New(),
+		windowCap:    windowCap,
+		protectedCap: mainCap * 8 / 10,
+		mainCap:      mainCap,
+		maxCost:      o.maxCost,
+	}
+}
+
+// Get looks up a key's value from the cache.
+func (c *Cache[K, V]) Get(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		c.lfu.increment(c.hasher.Hash(key))
+		return
+	}
+	ent := e.Value.(*entry[K, V])
+	c.lfu.increment(ent.hash)
+	c.access(e)
+	return ent.val, true
+}
+
+// Peek looks up a key's value from the cache without recording
+// the access to the frequency sketch and the segments.
+func (c *Cache[K, V]) Peek(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	return e.Value.(*entry[K, V]).val, true
+}
+
+// Set sets a value to the cache with key. replacing any existing value.
+//
+// If the cache is created with WithMaxCost option, items are evicted from
+// the probation segment, the protected segment and then the window, in order
+// from the oldest, until the total cost is within the max cost.
+func (c *Cache[K, V]) Set(key K, val V) {
+	cost := policyutil.GetCost(val)
+	if c.maxCost > 0 && cost > c.maxCost {
+		// the item never fits in the cache.
+		c.Delete(key)
+		if c.onEvicted != nil {
+			c.onEvicted(key, val)
+		}
+		return
+	}
+
+	if e, ok := c.items[key]; ok {
+		ent := e.Value.(*entry[K, V])
+		c.cost += cost - ent.cost
+		ent.val = val
+		ent.cost = cost
+		c.lfu.increment(ent.hash)
+		c.access(e)
+		c.evictForCost(c.items[key])
+		return
+	}
+
+	newEntry := &entry[K, V]{
+		key:     key,
+		val:     val,
+		hash:    c.hasher.Hash(key),
+		cost:    cost,
+		segment: windowSegment,
+	}
+	c.lfu.increment(newEntry.hash)
+	c.items[key] = c.window.PushFront(newEntry)
+	c.cost += cost
+
+	if c.window.Len() > c.windowCap {
+		c.admit(c.window.Back())
+	}
+	c.evictForCost(c.items[key])
+}
+
+// SetOnEvicted sets a callback which is called with the entry evicted
+// by the replacement policy when the cache is over the capacity.
+func (c *Cache[K, V]) SetOnEvicted(f func(key K, val V)) {
+	c.onEvicted = f
+}
+
+// Keys returns the keys of the cache. the order is from oldest to newest in the
+// window, followed by the probation segment and the protected segment.
+func (c *Cache[K, V]) Keys() []K {
+	keys := make([]K, 0, len(c.items))
+	for _, l := range []*list.List{c.window, c.probation, c.protected} {
+		for e := l.Back(); e != nil; e = e.Prev() {
+			keys = append(keys, e.Value.(*entry[K, V]).key)
+		}
+	}
+	return keys
+}
+
+// Delete deletes the item with provided key from the cache.
+func (c *Cache[K, V]) Delete(key K) {
+	if e, ok := c.items[key]; ok {
+		c.remove(e)
+	}
+}
+
+// Clear removes all items from the cache without calling the eviction
+// callback. The access frequencies estimated so far are also discarded.
+func (c *Cache[K, V]) Clear() {
+	c.window.Init()
+	c.probation.Init()
+	c.protected.Init()
+	c.cost = 0
+	c.items = make(map[K]*list.Element, c.windowCap+c.mainCap)
+	c.lfu = newTinyLFU(c.windowCap + c.mainCap)
+}
+
+// Len returns the number of items in the cache.
+func (c *Cache[K, V]) Len() int {
+	return len(c.items)
+}
+
+// access updates the position of the accessed element.
+func (c *Cache[K, V]) access(e *list.Element) {
+	ent := e.Value.(*entry[K, V])
+	switch ent.segment {
+	case windowSegment:
+		c.window.MoveToFront(e)
+	case probationSegment:
+		// promotes to the protected segment.
+		c.probation.Remove(e)
+		ent.segment = protectedSegment
+		c.items[ent.key] = c.protected.PushFront(ent)
+		if c.protected.Len() > c.protectedCap {
+			// demotes the oldest protected item to the probation segment.
+			demoted := c.protected.Back()
+			demotedEntry := c.protected.Remove(demoted).(*entry[K, V])
+			demotedEntry.segment = probationSegment
+			c.items[demotedEntry.key] = c.probation.PushFront(demotedEntry)
+		}
+	case protectedSegment:
+		c.protected.MoveToFront(e)
+	}
+}
+
+// admit moves the candidate evicted from the window to the main space if
+// TinyLFU estimates it is accessed more frequently than the victim of the
+// main space. Either the candidate or the victim is evicted.
+func (c *Cache[K, V]) admit(candidate *list.Element) {
+	candidateEntry := c.window.Remove(candidate).(*entry[K, V])
+	candidateEntry.segment = probationSegment
+	c.items[candidateEntry.key] = c.probation.PushFront(candidateEntry)
+
+	if c.probation.Len()+c.protected.Len() <= c.mainCap {
+		return
+	}
+
+	victim := c.probation.Back()
+	if victim == c.items[candidateEntry.key] {
+		// the probation segment has only the candidate.
+		if c.protected.Len() == 0 {
+			c.evict(victim)
+			return
+		}
+		victim = c.protected.Back()
+	}
+	victimEntry := victim.Value.(*entry[K, V])
+	if c.lfu.estimate(candidateEntry.hash) > c.lfu.estimate(victimEntry.hash) {
+		c.evict(victim)
+	} else {
+		c.evict(c.items[candidateEntry.key])
+	}
+}
+
+// evictForCost evicts the oldest items other than keep until the total cost
+// is within the max cost.
+func (c *Cache[K, V]) evictForCost(keep *list.Element) {
+	for c.maxCost > 0 && c.cost > c.maxCost {
+		var victim *list.Element
+		for _, l := range []*list.List{c.probation, c.protected, c.window} {
+			e := l.Back()
+			if e != nil && e == keep {
+				e = e.Prev()
+			}
+			if e != nil {
+				victim = e
+				break
+			}
+		}
+		if victim == nil {
+			return
+		}
+		c.evict(victim)
+	}
+}
+
+func (c *Cache[K, V]) evict(e *list.Element) {
+	ent := c.remove(e)
+	if c.onEvicted != nil {
+		c.onEvicted(ent.key, ent.val)
+	}
+}
+
+func (c *Cache[K, V]) remove(e *list.Element) *entry[K, V] {
+	ent := e.Value.(*entry[K, V])
+	switch ent.segment {
+	case windowSegment:
+		c.window.Remove(e)
+	case probationSegment:
+		c.probation.Remove(e)
+	case protectedSegment:
+		c.protected.Remove(e)
+	}
+	delete(c.items, ent.key)
+	c.cost -= ent.cost
+	return ent
+}
@@ -0,0 +1,248 @@
+package tinylfu_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Code-Hex/go-generics-cache/policy/tinylfu"
+)
+
+func TestSet(t *testing.T) {
+	// set capacity is 1
+	cache := tinylfu.NewCache[string, int](tinylfu.WithCapacity(1))
+	cache.Set("foo", 1)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got, ok := cache.Get("foo"); got != 1 || !ok {
+		t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+	}
+
+	// if over the cap
+	cache.Set("bar", 2)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	bar, ok := cache.Get("bar")
+	if bar != 2 || !ok {
+		t.Fatalf("invalid value bar %d, cachehit %v", bar, ok)
+	}
+
+	// checks deleted oldest
+	if _, ok := cache.Get("foo"); ok {
+		t.Fatalf("invalid delete oldest value foo %v", ok)
+	}
+
+	// valid: if over the cap but same key
+	cache.Set("bar", 100)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	bar, ok = cache.Get("bar")
+	if bar != 100 || !ok {
+		t.Fatalf("invalid replacing value bar %d, cachehit %v", bar, ok)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cache := tinylfu.NewCache[string, int](tinylfu.WithCapacity(1))
+	cache.Set("foo", 1)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+
+	cache.Delete("foo2")
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length after deleted does not exist key: %d", got)
+	}
+
+	cache.Delete("foo")
+	if got := cache.Len(); got != 0 {
+		t.Fatalf("invalid length after deleted: %d", got)
+	}
+	if _, ok := cache.Get("foo"); ok {
+		t.Fatalf("invalid get after deleted %v", ok)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	cache := tinylfu.NewCache[string, int](tinylfu.WithCapacity(10))
+	cache.Set("foo", 1)
+	cache.Set("bar", 2) // "foo" is moved to the probation segment
+	cache.Set("baz", 3) // "bar" is moved to the probation segment
+	cache.Get("foo")    // promotes to the protected segment
+
+	got := strings.Join(cache.Keys(), ",")
+	want := strings.Join([]string{
+		"baz", // window
+		"bar", // probation
+		"foo", // protected
+	}, ",")
+	if got != want {
+		t.Errorf("want %q, but got %q", want, got)
+	}
+	if len(cache.Keys()) != cache.Len() {
+		t.Errorf("want number of keys %d, but got %d", len(cache.Keys()), cache.Len())
+	}
+}
+
+func TestAdmission(t *testing.T) {
+	cache := tinylfu.NewCache[string, int](tinylfu.WithCapacity(100))
+	hot := []string{"hot1", "hot2", "hot3"}
+	for _, key := range hot {
+		cache.Set(key, 0)
+	}
+	for i := 0; i < 10; i++ {
+		for _, key := range hot {
+			cache.Get(key)
+		}
+	}
+
+	// items accessed only once must not flush frequently used items.
+	for i := 0; i < 1000; i++ {
+		cache.Set(strconv.Itoa(i), i)
+	}
+
+	for _, key := range hot {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("want %q is not evicted", key)
+		}
+	}
+	if got := cache.Len(); got != 100 {
+		t.Errorf("invalid length: %d", got)
+	}
+}
+
+func TestSetOnEvicted(t *testing.T) {
+	cache := tinylfu.NewCache[string, int](tinylfu.WithCapacity(2))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, val int) {
+		evicted = append(evicted, key+"="+strconv.Itoa(val))
+	})
+	cache.Set("foo", 1)
+	cache.Get("foo")
+	cache.Set("foo", 2) // replacing is not an eviction
+	cache.Set("bar", 3)
+	cache.Set("baz", 4) // "bar" is rejected because "foo" is used more frequently
+	cache.Delete("foo") // deletion is not an eviction
+
+	got := strings.Join(evicted, ",")
+	if want := "bar=3"; want != got {
+		t.Errorf("want evicted %q, but got %q", want, got)
+	}
+}
+
+type weighted int
+
+func (w weighted) GetCost() int64 { return int64(w) }
+
+func TestMaxCost(t *testing.T) {
+	cache := tinylfu.NewCache[string, weighted](tinylfu.WithMaxCost(10))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ weighted) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("foo", 4)
+	cache.Set("bar", 4)
+	cache.Set("baz", 4) // over the max cost
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+
+	// never stored because it exceeds the max cost by itself.
+	cache.Set("huge", 11)
+	if _, ok := cache.Get("huge"); ok {
+		t.Fatalf("want huge is rejected")
+	}
+
+	// replacing with a heavier value evicts the other items.
+	cache.Set("bar", 10)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got, ok := cache.Get("bar"); got != 10 || !ok {
+		t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+	}
+
+	got := strings.Join(evicted, ",")
+	if want := "foo,huge,baz"; want != got {
+		t.Errorf("want evicted %q, but got %q", want, got)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	if _, ok := tinylfu.NewCache[string, int]().Peek("foo"); ok {
+		t.Fatalf("want foo is not found")
+	}
+
+	cases := []struct {
+		name   string
+		access func(c *tinylfu.Cache[string, int], key string) (int, bool)
+		want   string
+	}{
+		{
+			// getting "bar" in the window increments the sketch, so it is admitted.
+			name:   "get",
+			access: (*tinylfu.Cache[string, int]).Get,
+			want:   "foo",
+		},
+		{
+			// peeking "bar" does not increment the sketch, so it is not admitted.
+			name:   "peek",
+			access: (*tinylfu.Cache[string, int]).Peek,
+			want:   "bar",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cache := tinylfu.NewCache[string, int](tinylfu.WithCapacity(2))
+			var evicted []string
+			cache.SetOnEvicted(func(key string, _ int) {
+				evicted = append(evicted, key)
+			})
+			cache.Set("foo", 1)
+			cache.Set("bar", 2)
+			for i := 0; i < 3; i++ {
+				if got, ok := tc.access(cache, "bar"); got != 2 || !ok {
+					t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+				}
+			}
+			cache.Set("baz", 3)
+			if got := strings.Join(evicted, ","); got != tc.want {
+				t.Errorf("want evicted %q, but got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestClear(t *testing.T) {
+	cache := tinylfu.NewCache[string, int](tinylfu.WithCapacity(2))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("foo", 1)
+	for i := 0; i < 10; i++ {
+		cache.Get("bar")
+	}
+	cache.Clear()
+	if got := cache.Len(); got != 0 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := cache.Keys(); len(got) != 0 {
+		t.Fatalf("want no keys, but got %v", got)
+	}
+	if len(evicted) != 0 {
+		t.Fatalf("want clearing is not an eviction, but got %v", evicted)
+	}
+
+	// the frequencies in the sketch are also dropped, so "bar" is not
+	// estimated more frequent than "foo" and is not admitted.
+	cache.Set("foo", 2)
+	cache.Set("bar", 3)
+	cache.Set("baz", 4)
+	if got := strings.Join(evicted, ","); got != "bar" {
+		t.Errorf("want evicted %q, but got %q", "bar", got)
+	}
+}
@@ -0,0 +1,38 @@
+package twoq_test
+
+import (
+	"fmt"
+
+	"github.com/Code-Hex/go-generics-cache/policy/twoq"
+)
+
+func ExampleNewCache() {
+	c := twoq.NewCache[string, int]()
+	c.Set("a", 1)
+	c.Set("b", 2)
+	av, aok := c.Get("a")
+	bv, bok := c.Get("b")
+	cv, cok := c.Get("c")
+	fmt.Println(av, aok)
+	fmt.Println(bv, bok)
+	fmt.Println(cv, cok)
+	// Output:
+	// 1 true
+	// 2 true
+	// 0 false
+}
+
+func ExampleCache_Keys() {
+	c := twoq.NewCache[string, int](twoq.WithCapacity(2))
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3) // "a" is evicted and remembered in A1out
+	c.Set("a", 4) // "a" is inserted to Am
+	keys := c.Keys()
+	for _, key := range keys {
+		fmt.Println(key)
+	}
+	// Output:
+	// c
+	// a
+}
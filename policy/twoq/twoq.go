@@ -0,0 +1,276 @@
+package twoq
+
+import (
+	"container/list"
+)
+
+// Cache is used a 2Q cache replacement policy.
+//
+// 2Q keeps the resident items in two queues. A1in is a FIFO queue for items
+// seen once recently, and Am is an LRU list for items seen at least twice.
+// A1out is a "ghost" FIFO queue which holds only the keys evicted from A1in.
+// A new item is placed in A1in, and it is placed in Am only if its key is
+// found in A1out, so that items used once by a scan never flush Am.
+//
+// See: https://www.vldb.org/conf/1994/P439.PDF
+type Cache[K comparable, V any] struct {
+	cap int
+	// kin is the threshold size of A1in.
+	kin int
+	// kout is the maximum size of A1out.
+	kout int
+
+	kinRatio  float64
+	koutRatio float64
+
+	a1in  *list.List // resident, FIFO
+	am    *list.List // resident, LRU
+	a1out *list.List // ghost, evicted from a1in
+
+	items  map[K]*list.Element // elements in a1in or am
+	ghosts map[K]*list.Element // elements in a1out
+
+	onEvicted func(key K, val V)
+}
+
+type entry[K comparable, V any] struct {
+	key K
+	val V
+	// list is the list which the entry belongs to.
+	list *list.List
+}
+
+// Option is an option for 2Q cache.
+type Option func(*options)
+
+type options struct {
+	capacity  int
+	kinRatio  float64
+	koutRatio float64
+}
+
+func newOptions() *options {
+	return &options{
+		capacity:  128,
+		kinRatio:  0.25,
+		koutRatio: 0.5,
+	}
+}
+
+// WithCapacity is an option to set cache capacity.
+func WithCapacity(cap int) Option {
+	return func(o *options) {
+		o.capacity = cap
+	}
+}
+
+// WithKinRatio is an option to set the ratio of the capacity which is the
+// threshold size of A1in. Items are evicted from A1in while it is over the
+// threshold, otherwise from Am. The ratio must be between 0 and 1, otherwise
+// it is ignored.
+//
+// The default is 0.25.
+func WithKinRatio(ratio float64) Option {
+	return func(o *options) {
+		if ratio >= 0 && ratio <= 1 {
+			o.kinRatio = ratio
+		}
+	}
+}
+
+// WithKoutRatio is an option to set the ratio of the capacity which is the
+// maximum number of keys remembered in A1out. A1out holds only keys, so the
+// ratio can be greater than 1. A negative ratio is ignored.
+//
+// The default is 0.5.
+func WithKoutRatio(ratio float64) Option {
+	return func(o *options) {
+		if ratio >= 0 {
+			o.koutRatio = ratio
+		}
+	}
+}
+
+// NewCache creates a new non-thread safe 2Q cache whose capacity is the default size (128).
+func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
+	o := newOptions()
+	for _, optFunc := range opts {
+		optFunc(o)
+	}
+	c := &Cache[K, V]{
+		kinRatio:  o.kinRatio,
+		koutRatio: o.koutRatio,
+		a1in:      list.New(),
+		am:        list.New(),
+		a1out:     list.New(),
+		items:     make(map[K]*list.Element, o.capacity),
+		ghosts:    make(map[K]*list.Element),
+	}
+	c.setCapacity(o.capacity)
+	return c
+}
+
+func (c *Cache[K, V]) setCapacity(cap int) {
+	c.cap = cap
+	c.kin = int(float64(cap) * c.kinRatio)
+	c.kout = int(float64(cap) * c.koutRatio)
+}
+
+// Get looks up a key's value from the cache.
+//
+// The item in Am is moved to the most recently used position. The item in
+// A1in is left as it is, since the accesses to it are likely correlated.
+func (c *Cache[K, V]) Get(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	ent := e.Value.(*entry[K, V])
+	if ent.list == c.am {
+		c.am.MoveToFront(e)
+	}
+	return ent.val, true
+}
+
+// Peek looks up a key's value from the cache without updating the cache order.
+func (c *Cache[K, V]) Peek(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	return e.Value.(*entry[K, V]).val, true
+}
+
+// Set sets a value to the cache with key. replacing any existing value.
+//
+// A new item is placed in Am if its key is remembered in A1out,
+// otherwise in A1in.
+func (c *Cache[K, V]) Set(key K, val V) {
+	if e, ok := c.items[key]; ok {
+		ent := e.Value.(*entry[K, V])
+		ent.val = val
+		if ent.list == c.am {
+			c.am.MoveToFront(e)
+		}
+		return
+	}
+
+	to := c.a1in
+	if e, ok := c.ghosts[key]; ok {
+		c.a1out.Remove(e)
+		delete(c.ghosts, key)
+		to = c.am
+	}
+	for len(c.items) >= c.cap && len(c.items) > 0 {
+		c.evict()
+	}
+	c.items[key] = to.PushFront(&entry[K, V]{
+		key:  key,
+		val:  val,
+		list: to,
+	})
+}
+
+// SetOnEvicted sets a callback which is called with the entry evicted
+// by the replacement policy when the cache is over the capacity.
+func (c *Cache[K, V]) SetOnEvicted(f func(key K, val V)) {
+	c.onEvicted = f
+}
+
+// Keys returns the keys of the cache. the order is from oldest to newest in
+// A1in (seen once recently), followed by from oldest to newest in Am (frequently used).
+// The keys only remembered in A1out are not included.
+func (c *Cache[K, V]) Keys() []K {
+	keys := make([]K, 0, len(c.items))
+	for _, l := range []*list.List{c.a1in, c.am} {
+		for e := l.Back(); e != nil; e = e.Prev() {
+			keys = append(keys, e.Value.(*entry[K, V]).key)
+		}
+	}
+	return keys
+}
+
+// Delete deletes the item with provided key from the cache.
+func (c *Cache[K, V]) Delete(key K) {
+	if e, ok := c.items[key]; ok {
+		e.Value.(*entry[K, V]).list.Remove(e)
+		delete(c.items, key)
+	}
+	if e, ok := c.ghosts[key]; ok {
+		c.a1out.Remove(e)
+		delete(c.ghosts, key)
+	}
+}
+
+// Clear removes all items and ghost entries from the cache without calling
+// the eviction callback.
+func (c *Cache[K, V]) Clear() {
+	c.a1in.Init()
+	c.am.Init()
+	c.a1out.Init()
+	c.items = make(map[K]*list.Element, c.cap)
+	c.ghosts = make(map[K]*list.Element)
+}
+
+// Len returns the number of items in the cache.
+func (c *Cache[K, V]) Len() int {
+	return len(c.items)
+}
+
+// Resize changes the capacity of the cache. The sizes of A1in and A1out are
+// also changed by the ratios. If the number of items exceeds the new capacity,
+// items are evicted as Set does and reported to the eviction callback.
+// It returns the number of evicted items.
+// If cap is less than 1, it is treated as 1.
+func (c *Cache[K, V]) Resize(cap int) (evicted int) {
+	if cap < 1 {
+		cap = 1
+	}
+	c.setCapacity(cap)
+	for len(c.items) > cap && len(c.items) > 0 {
+		c.evict()
+		evicted++
+	}
+	for c.a1out.Len() > c.kout {
+		c.removeGhost()
+	}
+	return evicted
+}
+
+// evict evicts the oldest item in A1in and remembers its key in A1out if
+// A1in is over the threshold size. Otherwise it evicts the least recently
+// used item in Am.
+func (c *Cache[K, V]) evict() {
+	if c.a1in.Len() > c.kin || c.am.Len() == 0 {
+		e := c.a1in.Back()
+		ent := c.remove(e)
+		var zero V
+		ent.val = zero // ghost entries keep only keys
+		ent.list = c.a1out
+		c.ghosts[ent.key] = c.a1out.PushFront(ent)
+		for c.a1out.Len() > c.kout {
+			c.removeGhost()
+		}
+		return
+	}
+	c.remove(c.am.Back())
+}
+
+// remove removes the resident element from the cache and reports it to
+// the eviction callback.
+func (c *Cache[K, V]) remove(e *list.Element) *entry[K, V] {
+	ent := e.Value.(*entry[K, V])
+	ent.list.Remove(e)
+	delete(c.items, ent.key)
+	if c.onEvicted != nil {
+		c.onEvicted(ent.key, ent.val)
+	}
+	return ent
+}
+
+// removeGhost removes the oldest key from A1out.
+func (c *Cache[K, V]) removeGhost() {
+	e := c.a1out.Back()
+	c.a1out.Remove(e)
+	delete(c.ghosts, e.Value.(*entry[K, V]).key)
+}
@@ -0,0 +1,333 @@
+package twoq_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Code-Hex/go-generics-cache/policy/twoq"
+)
+
+func TestSet(t *testing.T) {
+	// set capacity is 1
+	cache := twoq.NewCache[string, int](twoq.WithCapacity(1))
+	cache.Set("foo", 1)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got, ok := cache.Get("foo"); got != 1 || !ok {
+		t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+	}
+
+	// if over the cap
+	cache.Set("bar", 2)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	bar, ok := cache.Get("bar")
+	if bar != 2 || !ok {
+		t.Fatalf("invalid value bar %d, cachehit %v", bar, ok)
+	}
+
+	// checks deleted oldest
+	if _, ok := cache.Get("foo"); ok {
+		t.Fatalf("invalid delete oldest value foo %v", ok)
+	}
+
+	// valid: if over the cap but same key
+	cache.Set("bar", 100)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	bar, ok = cache.Get("bar")
+	if bar != 100 || !ok {
+		t.Fatalf("invalid replacing value bar %d, cachehit %v", bar, ok)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cache := twoq.NewCache[string, int](twoq.WithCapacity(1))
+	cache.Set("foo", 1)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+
+	cache.Delete("foo2")
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length after deleted does not exist key: %d", got)
+	}
+
+	cache.Delete("foo")
+	if got := cache.Len(); got != 0 {
+		t.Fatalf("invalid length after deleted: %d", got)
+	}
+	if _, ok := cache.Get("foo"); ok {
+		t.Fatalf("invalid get after deleted %v", ok)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	cache := twoq.NewCache[string, int](twoq.WithCapacity(4))
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		cache.Set(key, 0) // "a" is evicted to A1out
+	}
+	cache.Set("a", 1) // "a" is remembered, so it is inserted to Am
+	cache.Get("c")    // A1in is FIFO, so the order is not changed
+
+	got := strings.Join(cache.Keys(), ",")
+	want := strings.Join([]string{
+		"c", "d", "e", // A1in
+		"a", // Am
+	}, ",")
+	if got != want {
+		t.Errorf("want %q, but got %q", want, got)
+	}
+	if len(cache.Keys()) != cache.Len() {
+		t.Errorf("want number of keys %d, but got %d", len(cache.Keys()), cache.Len())
+	}
+}
+
+func TestScanResistance(t *testing.T) {
+	cache := twoq.NewCache[string, int](twoq.WithCapacity(4))
+	for _, key := range []string{"hot1", "hot2"} {
+		cache.Set(key, 0)
+	}
+	for i := 0; i < 4; i++ {
+		cache.Set("warmup"+strconv.Itoa(i), i)
+	}
+	// hot items are used again after they are evicted to A1out.
+	for _, key := range []string{"hot1", "hot2"} {
+		cache.Set(key, 0)
+	}
+
+	// one-time scan must not flush frequently used items.
+	for i := 0; i < 100; i++ {
+		cache.Set(strconv.Itoa(i), i)
+	}
+
+	for _, key := range []string{"hot1", "hot2"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("want %q is not evicted by scan", key)
+		}
+	}
+	if got := cache.Len(); got != 4 {
+		t.Errorf("invalid length: %d", got)
+	}
+}
+
+func TestKoutRatio(t *testing.T) {
+	cases := []struct {
+		name  string
+		ratio float64
+		want  bool
+	}{
+		{
+			name:  "remembered",
+			ratio: 0.5, // A1out holds 2 keys
+			want:  true,
+		},
+		{
+			name:  "forgotten",
+			ratio: 0.25, // A1out holds 1 key
+			want:  false,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cache := twoq.NewCache[string, int](
+				twoq.WithCapacity(4),
+				twoq.WithKoutRatio(tc.ratio),
+			)
+			for _, key := range []string{"a", "b", "c", "d", "e", "f"} {
+				cache.Set(key, 0) // "a" and then "b" are evicted to A1out
+			}
+			cache.Set("a", 1)
+			for i := 0; i < 10; i++ {
+				cache.Set(strconv.Itoa(i), i)
+			}
+			// "a" survives the scan only if it has been inserted to Am.
+			if _, got := cache.Get("a"); got != tc.want {
+				t.Errorf("want cachehit %v, but got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestKinRatio(t *testing.T) {
+	// A1in can hold all items until the cache is full.
+	cache := twoq.NewCache[string, int](
+		twoq.WithCapacity(4),
+		twoq.WithKinRatio(1),
+	)
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		cache.Set(key, 0) // "a" is evicted to A1out
+	}
+	cache.Set("a", 1) // "a" is inserted to Am
+	cache.Set("f", 2)
+
+	// A1in is not over the threshold, so the item in Am is evicted.
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("want a is evicted from Am")
+	}
+	got := strings.Join(cache.Keys(), ",")
+	if want := "c,d,e,f"; want != got {
+		t.Errorf("want %q, but got %q", want, got)
+	}
+}
+
+func TestSetOnEvicted(t *testing.T) {
+	cache := twoq.NewCache[string, int](twoq.WithCapacity(2))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, val int) {
+		evicted = append(evicted, key+"="+strconv.Itoa(val))
+	})
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Get("foo")
+	cache.Set("foo", 3) // replacing is not an eviction
+	cache.Set("baz", 4)
+	cache.Delete("bar") // deletion is not an eviction
+
+	got := strings.Join(evicted, ",")
+	if want := "foo=3"; want != got {
+		t.Errorf("want evicted %q, but got %q", want, got)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	if _, ok := twoq.NewCache[string, int]().Peek("foo"); ok {
+		t.Fatalf("want foo is not found")
+	}
+
+	cases := []struct {
+		name   string
+		access func(c *twoq.Cache[string, int], key string) (int, bool)
+		want   string
+	}{
+		{
+			// getting "foo" makes it the most recently used in Am.
+			name:   "get",
+			access: (*twoq.Cache[string, int]).Get,
+			want:   "bar",
+		},
+		{
+			// peeking "foo" leaves it the least recently used in Am.
+			name:   "peek",
+			access: (*twoq.Cache[string, int]).Peek,
+			want:   "foo",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cache := twoq.NewCache[string, int](twoq.WithCapacity(2))
+			var evicted []string
+			cache.SetOnEvicted(func(key string, _ int) {
+				evicted = append(evicted, key)
+			})
+			cache.Set("foo", 1)
+			cache.Set("bar", 2)
+			cache.Set("qux", 0) // "foo" is evicted to A1out
+			cache.Set("foo", 1) // "foo" is placed in Am, and "bar" is evicted to A1out
+			cache.Set("bar", 2) // "bar" is placed in Am, and "qux" is evicted to A1out
+			evicted = nil
+			for i := 0; i < 3; i++ {
+				if got, ok := tc.access(cache, "foo"); got != 1 || !ok {
+					t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+				}
+			}
+			cache.Set("baz", 3)
+			if got := strings.Join(evicted, ","); got != tc.want {
+				t.Errorf("want evicted %q, but got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestResize(t *testing.T) {
+	cache := twoq.NewCache[string, int](twoq.WithCapacity(3))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Set("baz", 3)
+	if got := cache.Resize(1); got != 2 {
+		t.Fatalf("want 2 evicted items, but got %d", got)
+	}
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := strings.Join(evicted, ","); got != "foo,bar" {
+		t.Errorf("want evicted %q, but got %q", "foo,bar", got)
+	}
+
+	// growing keeps the items.
+	if got := cache.Resize(3); got != 0 {
+		t.Fatalf("want no evicted items, but got %d", got)
+	}
+	cache.Set("qux", 4)
+	cache.Set("quux", 5)
+	if got := cache.Len(); got != 3 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := len(evicted); got != 2 {
+		t.Errorf("want no more evictions, but got %v", evicted)
+	}
+}
+
+func TestResizeLessThanOne(t *testing.T) {
+	for _, cap := range []int{0, -1} {
+		cache := twoq.NewCache[string, int](twoq.WithCapacity(3))
+		cache.Set("foo", 1)
+		cache.Set("bar", 2)
+		// treated as 1.
+		if got := cache.Resize(cap); got != 1 {
+			t.Fatalf("Resize(%d): want 1 evicted item, but got %d", cap, got)
+		}
+		cache.Set("baz", 3)
+		if got := cache.Len(); got != 1 {
+			t.Fatalf("Resize(%d): invalid length: %d", cap, got)
+		}
+		if got, ok := cache.Get("baz"); got != 3 || !ok {
+			t.Fatalf("Resize(%d): invalid value got %d, cachehit %v", cap, got, ok)
+		}
+	}
+}
+
+func TestClear(t *testing.T) {
+	cache := twoq.NewCache[string, int](twoq.WithCapacity(2))
+	var evicted []string
+	cache.SetOnEvicted(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Set("baz", 3) // "foo" is evicted to A1out
+	evicted = nil
+	cache.Clear()
+	if got := cache.Len(); got != 0 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := cache.Keys(); len(got) != 0 {
+		t.Fatalf("want no keys, but got %v", got)
+	}
+	if _, ok := cache.Get("bar"); ok {
+		t.Fatalf("want bar is cleared")
+	}
+	if len(evicted) != 0 {
+		t.Fatalf("want clearing is not an eviction, but got %v", evicted)
+	}
+
+	// the ghost entries are also cleared, so "foo" is inserted to A1in.
+	cache.Set("foo", 4)
+	cache.Set("qux", 5)
+	cache.Set("quux", 6)
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got := strings.Join(evicted, ","); got != "foo" {
+		t.Errorf("want evicted %q, but got %q", "foo", got)
+	}
+}
@@ -0,0 +1,40 @@
+package cache
+
+import "time"
+
+// Items returns a snapshot of the items in the cache which have not expired.
+// The order is the same as Keys. Getting the items does not affect the
+// eviction order of the cache policy.
+//
+// If the cache has been closed, it returns nil.
+func (c *Cache[K, V]) Items() []Item[K, V] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	keys := c.cache.Keys()
+	items := make([]Item[K, V], 0, len(keys))
+	for _, key := range keys {
+		item, ok := c.cache.Peek(key)
+		if !ok || item.Expired() {
+			continue
+		}
+		items = append(items, *item)
+	}
+	return items
+}
+
+// Range calls f sequentially for each key, value and expiration time of the
+// items in the cache which have not expired. The expiration time is zero if
+// the item never expires. If f returns false, Range stops the iteration.
+//
+// Range iterates over a snapshot taken by Items, so f may call methods of
+// the cache, and the changes made during the iteration are not reflected.
+func (c *Cache[K, V]) Range(f func(key K, val V, exp time.Time) bool) {
+	for _, item := range c.Items() {
+		if !f(item.Key, item.Value, item.Expiration) {
+			return
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package cache_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	cache "github.com/Code-Hex/go-generics-cache"
+	"github.com/Code-Hex/go-generics-cache/cachetest"
+	"github.com/Code-Hex/go-generics-cache/policy/lru"
+)
+
+func TestItems(t *testing.T) {
+	clk := cachetest.NewFakeClock(time.Now())
+	c := cache.New(
+		cache.AsLRU[string, int](lru.WithCapacity(3)),
+		cache.WithClock[string, int](clk),
+	)
+	c.Set("a", 1)
+	c.Set("b", 2, cache.WithExpiration(time.Hour))
+	c.Set("c", 3, cache.WithExpiration(time.Second))
+	clk.Advance(2 * time.Second)
+
+	items := c.Items()
+	if len(items) != 2 {
+		t.Fatalf("want 2 items but got %d", len(items))
+	}
+	if items[0].Key != "a" || items[0].Value != 1 || !items[0].Expiration.IsZero() {
+		t.Errorf("unexpected item: %+v", items[0])
+	}
+	if items[1].Key != "b" || items[1].Value != 2 || items[1].Expiration.IsZero() {
+		t.Errorf("unexpected item: %+v", items[1])
+	}
+
+	// iterating does not promote "a", which is still the least recently used.
+	c.Set("d", 4)
+	if c.Contains("a") {
+		t.Errorf("want a is evicted")
+	}
+}
+
+func TestRange(t *testing.T) {
+	c := cache.New(cache.AsLRU[string, int]())
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+
+	var keys []string
+	c.Range(func(key string, val int, exp time.Time) bool {
+		keys = append(keys, key)
+		// the cache can be updated during the iteration.
+		c.Delete(key)
+		return key != "b"
+	})
+	if want := []string{"a", "b"}; !reflect.DeepEqual(want, keys) {
+		t.Errorf("want %v but got %v", want, keys)
+	}
+	if want, got := []string{"c"}, c.Keys(); !reflect.DeepEqual(want, got) {
+		t.Errorf("want keys %v but got %v", want, got)
+	}
+}
+
+func TestShardedRange(t *testing.T) {
+	c := cache.NewSharded[int, int](4)
+	for i := 0; i < 100; i++ {
+		c.Set(i, i)
+	}
+	if got := len(c.Items()); got != 100 {
+		t.Errorf("want 100 items but got %d", got)
+	}
+	n := 0
+	c.Range(func(key, val int, _ time.Time) bool {
+		n++
+		return n < 10
+	})
+	if n != 10 {
+		t.Errorf("want the iteration is stopped at 10 but got %d", n)
+	}
+}
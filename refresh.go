@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNoLoader is returned by GetOrLoad and GetOrLoadStale when they are called
+// with nil loader and no loader is registered by WithLoader.
+var ErrNoLoader = errors.New("cache: no loader")
+
+// WithLoader is an option to register a loader of the cache. The loader is
+// used by GetOrLoad when it is called with nil loader, and to reload items
+// in the background when refresh-ahead is enabled by WithRefreshAhead.
+func WithLoader[K comparable, V any](loader LoaderFunc[K, V]) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.loader = loader
+	}
+}
+
+// WithRefreshAhead is an option to reload items before they expire.
+//
+// When an item is read by Get (or GetOrLoad) and the remaining time until
+// its expiration is within the fraction of its time to live, the cache
+// reloads the value in the background by the loader registered by WithLoader,
+// while the current value continues to be served. For example, 0.2 reloads
+// an item which has been set with WithExpiration(time.Minute) when it is
+// read within the last 12 seconds of its lifetime. The reloaded value is set
+// with the same time to live.
+//
+// At most one reload is in flight per key at a time. Errors returned by the
+// loader are reported to the callback set by OnRefreshError, and the current
+// value is kept until it expires.
+//
+// Refresh-ahead applies only to items which have an expiration and no
+// sliding expiration. The reload is called with the context given to
+// NewContext. It is disabled if no loader is registered or the fraction is
+// not between 0 and 1 exclusive, which is the default.
+func WithRefreshAhead[K comparable, V any](fraction float64) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.refreshAhead = fraction
+	}
+}
+
+// OnRefreshError is an option to set a callback which is called with the
+// error returned by the loader when reloading an item in the background
+// by refresh-ahead or stale-while-revalidate.
+func OnRefreshError[K comparable, V any](f func(key K, err error)) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.onRefreshError = f
+	}
+}
+
+// needsRefresh reports whether the item should be reloaded ahead of its
+// expiration.
+func (c *Cache[K, V]) needsRefresh(item *Item[K, V]) bool {
+	if c.loader == nil || c.refreshAhead <= 0 || c.refreshAhead >= 1 || item.ttl <= 0 {
+		return false
+	}
+	remaining := item.Expiration.Sub(item.now())
+	return remaining <= time.Duration(float64(item.ttl)*c.refreshAhead)
+}
+
+// refreshAheadIfNeeded starts reloading the item in the background if it is
+// close to its expiration. c.mu must be held.
+func (c *Cache[K, V]) refreshAheadIfNeeded(key K, item *Item[K, V]) {
+	if !c.needsRefresh(item) {
+		return
+	}
+	c.reloadInBackground(key, item, c.loader, []ItemOption{
+		WithExpiration(item.ttl),
+		WithStaleWhileRevalidate(item.StaleWhileRevalidate),
+		WithStaleIfError(item.StaleIfError),
+	})
+}
+
+// reloadInBackground starts reloading the item by loader unless a reload is
+// in flight for the key. c.mu must be held.
+func (c *Cache[K, V]) reloadInBackground(key K, item *Item[K, V], loader LoaderFunc[K, V], opts []ItemOption) {
+	if _, ok := c.refreshing[key]; ok {
+		return
+	}
+	if c.refreshing == nil {
+		c.refreshing = make(map[K]struct{})
+	}
+	c.refreshing[key] = struct{}{}
+	go c.reload(key, item, loader, opts)
+}
+
+// reload reloads the value of the item and replaces the item with it
+// unless the item has been changed during the reload. Errors are reported
+// to the callback set by OnRefreshError.
+func (c *Cache[K, V]) reload(key K, old *Item[K, V], loader LoaderFunc[K, V], opts []ItemOption) {
+	val, err := c.loads.do(c.ctx, key, func() (V, error) {
+		val, err := loader(c.ctx, key)
+		c.stats.load(err)
+		return val, err
+	})
+
+	if err != nil {
+		c.mu.Lock()
+		delete(c.refreshing, key)
+		c.mu.Unlock()
+		if c.onRefreshError != nil {
+			c.onRefreshError(key, err)
+		}
+		return
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+	delete(c.refreshing, key)
+	if c.closed {
+		return
+	}
+	if cur, ok := c.cache.Peek(key); !ok || cur != old {
+		// the item has been deleted or set again during the reload.
+		return
+	}
+	c.evicted(key, old, EvictionReasonReplaced)
+	item := c.newItem(key, val, opts...)
+	c.trackExpiration(key, item)
+	c.cache.Set(key, item)
+}
@@ -0,0 +1,175 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cache "github.com/Code-Hex/go-generics-cache"
+	"github.com/Code-Hex/go-generics-cache/cachetest"
+)
+
+// waitFor waits until cond returns true.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRefreshAhead(t *testing.T) {
+	clk := cachetest.NewFakeClock(time.Now())
+	var calls int32
+	release := make(chan struct{})
+	c := cache.New(
+		cache.WithClock[string, int](clk),
+		cache.WithLoader(func(ctx context.Context, key string) (int, error) {
+			<-release
+			return int(atomic.AddInt32(&calls, 1)) * 10, nil
+		}),
+		cache.WithRefreshAhead[string, int](0.2),
+		cache.WithStats[string, int](),
+	)
+	defer c.Close()
+
+	c.Set("a", 1, cache.WithExpiration(10*time.Second))
+
+	// not within the last 2 seconds.
+	clk.Advance(7 * time.Second)
+	if got, ok := c.Get("a"); got != 1 || !ok {
+		t.Fatalf("want 1 but got %d, %v", got, ok)
+	}
+
+	// the current value is served while reloading.
+	clk.Advance(2 * time.Second)
+	for i := 0; i < 3; i++ {
+		if got, ok := c.Get("a"); got != 1 || !ok {
+			t.Fatalf("want 1 but got %d, %v", got, ok)
+		}
+	}
+	close(release)
+
+	waitFor(t, func() bool {
+		got, _ := c.Get("a")
+		return got == 10
+	})
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("want the loader is called once but got %d", got)
+	}
+	if got := c.Stats().Loads; got != 1 {
+		t.Errorf("want 1 load but got %d", got)
+	}
+
+	// the reloaded value is set with the same time to live.
+	clk.Advance(7 * time.Second)
+	if got, ok := c.Get("a"); got != 10 || !ok {
+		t.Fatalf("want 10 but got %d, %v", got, ok)
+	}
+	clk.Advance(4 * time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("want the reloaded value has expired")
+	}
+}
+
+func TestRefreshAheadError(t *testing.T) {
+	clk := cachetest.NewFakeClock(time.Now())
+	wantErr := errors.New("error")
+	errc := make(chan error, 1)
+	c := cache.New(
+		cache.WithClock[string, int](clk),
+		cache.WithLoader(func(ctx context.Context, key string) (int, error) {
+			return 0, wantErr
+		}),
+		cache.WithRefreshAhead[string, int](0.5),
+		cache.OnRefreshError[string, int](func(key string, err error) {
+			if key != "a" {
+				t.Errorf("want key a but got %q", key)
+			}
+			errc <- err
+		}),
+	)
+	defer c.Close()
+
+	c.Set("a", 1, cache.WithExpiration(time.Minute))
+	clk.Advance(45 * time.Second)
+	c.Get("a")
+
+	select {
+	case err := <-errc:
+		if !errors.Is(err, wantErr) {
+			t.Errorf("want %v but got %v", wantErr, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+
+	// the current value is kept.
+	if got, ok := c.Get("a"); got != 1 || !ok {
+		t.Fatalf("want 1 but got %d, %v", got, ok)
+	}
+}
+
+func TestRefreshAheadSkipsUpdatedItem(t *testing.T) {
+	clk := cachetest.NewFakeClock(time.Now())
+	loading := make(chan struct{})
+	release := make(chan struct{})
+	c := cache.New(
+		cache.WithClock[string, int](clk),
+		cache.WithLoader(func(ctx context.Context, key string) (int, error) {
+			close(loading)
+			<-release
+			return 100, nil
+		}),
+		cache.WithRefreshAhead[string, int](0.5),
+		cache.WithStats[string, int](),
+	)
+	defer c.Close()
+
+	c.Set("a", 1, cache.WithExpiration(time.Minute))
+	clk.Advance(45 * time.Second)
+	c.Get("a")
+	<-loading
+
+	// the value set during the reload is not overwritten.
+	c.Set("a", 2)
+	close(release)
+	waitFor(t, func() bool {
+		return c.Stats().Loads == 1
+	})
+	time.Sleep(10 * time.Millisecond)
+
+	if got, ok := c.Get("a"); got != 2 || !ok {
+		t.Fatalf("want 2 but got %d, %v", got, ok)
+	}
+}
+
+func TestGetOrLoadWithRegisteredLoader(t *testing.T) {
+	c := cache.New(
+		cache.WithLoader(func(ctx context.Context, key string) (int, error) {
+			return len(key), nil
+		}),
+	)
+	got, err := c.GetOrLoad(context.Background(), "hello", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 5 {
+		t.Errorf("want 5 but got %d", got)
+	}
+}
+
+func TestGetOrLoadWithoutLoader(t *testing.T) {
+	c := cache.New[string, int]()
+	c.Set("a", 1)
+	for _, key := range []string{"a", "b"} {
+		if _, err := c.GetOrLoad(context.Background(), key, nil); !errors.Is(err, cache.ErrNoLoader) {
+			t.Errorf("want %v for %q but got %v", cache.ErrNoLoader, key, err)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package cache
+
+// resizer is implemented by cache policies whose capacity can be changed.
+type resizer interface {
+	Resize(cap int) (evicted int)
+}
+
+// Resize changes the capacity of the cache policy at runtime. If the number
+// of items exceeds the new capacity, items are evicted in the order of the
+// policy and reported to the OnEvicted callback with EvictionReasonCapacity.
+// It returns the number of evicted items. If cap is less than 1, it is
+// treated as 1.
+//
+// Resizing is supported by LRU, LFU, FIFO, MRU, Clock, SLRU and 2Q policies.
+// For the other policies, or if the cache has been closed, it does nothing
+// and returns 0.
+func (c *Cache[K, V]) Resize(cap int) (evicted int) {
+	c.mu.Lock()
+	defer c.unlock()
+	if c.closed {
+		return 0
+	}
+	r, ok := c.cache.(resizer)
+	if !ok {
+		return 0
+	}
+	if cap < 1 {
+		cap = 1
+	}
+	return r.Resize(cap)
+}
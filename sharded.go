@@ -0,0 +1,328 @@
+package cache
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/Code-Hex/go-generics-cache/internal/hashutil"
+)
+
+// ShardedCache is a thread safe cache which partitions keys across several
+// shards. Each shard is an independent Cache with its own lock, so operations
+// on keys in different shards do not contend with each other.
+type ShardedCache[K comparable, V any] struct {
+	shards []*Cache[K, V]
+	hasher *hashutil.Hasher[K]
+}
+
+// NewSharded creates a new thread safe ShardedCache which has the specified
+// number of shards. If shards is less than 1, it is treated as 1.
+//
+// Each shard is created with the same options, so the options which specify
+// a capacity (e.g. lru.WithCapacity) limit the number of items per shard.
+func NewSharded[K comparable, V any](shards int, opts ...Option[K, V]) *ShardedCache[K, V] {
+	return NewShardedContext(context.Background(), shards, opts...)
+}
+
+// NewShardedContext creates a new thread safe ShardedCache with context.
+// This function will be stopped internal janitors when the context is cancelled.
+func NewShardedContext[K comparable, V any](ctx context.Context, shards int, opts ...Option[K, V]) *ShardedCache[K, V] {
+	if shards < 1 {
+		shards = 1
+	}
+	c := &ShardedCache[K, V]{
+		shards: make([]*Cache[K, V], shards),
+		hasher: hashutil.New[K](),
+	}
+	for i := range c.shards {
+		c.shards[i] = NewContext(ctx, opts...)
+	}
+	return c
+}
+
+func (c *ShardedCache[K, V]) shard(key K) *Cache[K, V] {
+	return c.shards[c.shardIndex(key)]
+}
+
+func (c *ShardedCache[K, V]) shardIndex(key K) int {
+	return int(c.hasher.Hash(key) % uint64(len(c.shards)))
+}
+
+// groupKeys groups the keys by the index of their shard.
+func (c *ShardedCache[K, V]) groupKeys(keys []K) [][]K {
+	groups := make([][]K, len(c.shards))
+	for _, key := range keys {
+		i := c.shardIndex(key)
+		groups[i] = append(groups[i], key)
+	}
+	return groups
+}
+
+// Get looks up a key's value from the cache.
+func (c *ShardedCache[K, V]) Get(key K) (value V, ok bool) {
+	return c.shard(key).Get(key)
+}
+
+// Peek looks up a key's value from the cache without affecting the
+// eviction order of the cache policy.
+func (c *ShardedCache[K, V]) Peek(key K) (value V, ok bool) {
+	return c.shard(key).Peek(key)
+}
+
+// GetOrSet atomically gets a key's value from the cache, or if the
+// key is not present, sets the given value.
+// The loaded result is true if the value was loaded, false if stored.
+func (c *ShardedCache[K, V]) GetOrSet(key K, val V, opts ...ItemOption) (actual V, loaded bool) {
+	return c.shard(key).GetOrSet(key, val, opts...)
+}
+
+// GetOrLoad gets a key's value from the cache, or if the key is not present,
+// calls loader to load the value and sets it to the cache.
+// See Cache.GetOrLoad for details.
+func (c *ShardedCache[K, V]) GetOrLoad(ctx context.Context, key K, loader LoaderFunc[K, V], opts ...ItemOption) (V, error) {
+	return c.shard(key).GetOrLoad(ctx, key, loader, opts...)
+}
+
+// GetOrLoadStale is like GetOrLoad, but also reports whether the returned
+// value is stale. See Cache.GetOrLoadStale for details.
+func (c *ShardedCache[K, V]) GetOrLoadStale(ctx context.Context, key K, loader LoaderFunc[K, V], opts ...ItemOption) (value V, stale bool, err error) {
+	return c.shard(key).GetOrLoadStale(ctx, key, loader, opts...)
+}
+
+// Set sets a value to the cache with key. replacing any existing value.
+func (c *ShardedCache[K, V]) Set(key K, val V, opts ...ItemOption) {
+	c.shard(key).Set(key, val, opts...)
+}
+
+// Keys returns the keys of the cache. the order is relied on algorithms
+// within each shard.
+func (c *ShardedCache[K, V]) Keys() []K {
+	var keys []K
+	for _, shard := range c.shards {
+		keys = append(keys, shard.Keys()...)
+	}
+	return keys
+}
+
+// Delete deletes the item with provided key from the cache.
+func (c *ShardedCache[K, V]) Delete(key K) {
+	c.shard(key).Delete(key)
+}
+
+// Len returns the number of items in the cache.
+func (c *ShardedCache[K, V]) Len() int {
+	n := 0
+	for _, shard := range c.shards {
+		n += shard.Len()
+	}
+	return n
+}
+
+// Compute atomically computes a new value for the key from the current value
+// and performs op returned by f. See Cache.Compute for details.
+func (c *ShardedCache[K, V]) Compute(key K, f func(old V, exists bool) (val V, op ComputeOp), opts ...ItemOption) (actual V, ok bool) {
+	return c.shard(key).Compute(key, f, opts...)
+}
+
+// ComputeIfAbsent is like Compute, but f is called only if the key is not
+// present or has expired.
+func (c *ShardedCache[K, V]) ComputeIfAbsent(key K, f func() (val V, op ComputeOp), opts ...ItemOption) (actual V, ok bool) {
+	return c.shard(key).ComputeIfAbsent(key, f, opts...)
+}
+
+// ComputeIfPresent is like Compute, but f is called only if the key is
+// present and has not expired.
+func (c *ShardedCache[K, V]) ComputeIfPresent(key K, f func(old V) (val V, op ComputeOp), opts ...ItemOption) (actual V, ok bool) {
+	return c.shard(key).ComputeIfPresent(key, f, opts...)
+}
+
+// SetMany sets multiple values to the cache with the same options, replacing
+// any existing values. Each shard is locked only once. It returns the keys
+// of the items which have not been stored. See Cache.SetMany for details.
+func (c *ShardedCache[K, V]) SetMany(items map[K]V, opts ...ItemOption) (rejected []K) {
+	groups := make([]map[K]V, len(c.shards))
+	for key, val := range items {
+		i := c.shardIndex(key)
+		if groups[i] == nil {
+			groups[i] = make(map[K]V)
+		}
+		groups[i][key] = val
+	}
+	for i, group := range groups {
+		if len(group) > 0 {
+			rejected = append(rejected, c.shards[i].SetMany(group, opts...)...)
+		}
+	}
+	return rejected
+}
+
+// GetMany looks up values of the keys from the cache. Each shard is locked
+// only once. See Cache.GetMany for details.
+func (c *ShardedCache[K, V]) GetMany(keys []K) (found map[K]V, missing []K) {
+	found = make(map[K]V, len(keys))
+	for i, group := range c.groupKeys(keys) {
+		if len(group) == 0 {
+			continue
+		}
+		values, _ := c.shards[i].GetMany(group)
+		for key, val := range values {
+			found[key] = val
+		}
+	}
+	for _, key := range keys {
+		if _, ok := found[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	return found, missing
+}
+
+// DeleteMany deletes the items with provided keys from the cache. Each shard
+// is locked only once. It returns the keys which have been deleted.
+func (c *ShardedCache[K, V]) DeleteMany(keys []K) (deleted []K) {
+	for i, group := range c.groupKeys(keys) {
+		if len(group) > 0 {
+			deleted = append(deleted, c.shards[i].DeleteMany(group)...)
+		}
+	}
+	return deleted
+}
+
+// Items returns a snapshot of the items in the cache which have not expired.
+// The order is relied on algorithms within each shard.
+func (c *ShardedCache[K, V]) Items() []Item[K, V] {
+	var items []Item[K, V]
+	for _, shard := range c.shards {
+		items = append(items, shard.Items()...)
+	}
+	return items
+}
+
+// Range calls f sequentially for each key, value and expiration time of the
+// items in the cache which have not expired. If f returns false, Range stops
+// the iteration. Each shard is iterated over a snapshot of the shard.
+func (c *ShardedCache[K, V]) Range(f func(key K, val V, exp time.Time) bool) {
+	for _, shard := range c.shards {
+		stopped := false
+		shard.Range(func(key K, val V, exp time.Time) bool {
+			stopped = !f(key, val, exp)
+			return !stopped
+		})
+		if stopped {
+			return
+		}
+	}
+}
+
+// Resize changes the capacity of each shard. It returns the total number
+// of evicted items. See Cache.Resize for details.
+func (c *ShardedCache[K, V]) Resize(cap int) (evicted int) {
+	for _, shard := range c.shards {
+		evicted += shard.Resize(cap)
+	}
+	return evicted
+}
+
+// Clear removes all items from the cache without reporting them to the
+// OnEvicted callback. Each shard is cleared one by one.
+func (c *ShardedCache[K, V]) Clear() {
+	for _, shard := range c.shards {
+		shard.Clear()
+	}
+}
+
+// Purge is like Clear, but reports the removed items to the OnEvicted
+// callback with EvictionReasonDeleted.
+func (c *ShardedCache[K, V]) Purge() {
+	for _, shard := range c.shards {
+		shard.Purge()
+	}
+}
+
+// Contains reports whether key is within cache.
+func (c *ShardedCache[K, V]) Contains(key K) bool {
+	return c.shard(key).Contains(key)
+}
+
+// DeleteExpired all expired items from the cache.
+func (c *ShardedCache[K, V]) DeleteExpired() {
+	for _, shard := range c.shards {
+		shard.DeleteExpired()
+	}
+}
+
+// Close closes all shards. See Cache.Close for details.
+// It returns ErrClosed if the cache has already been closed.
+func (c *ShardedCache[K, V]) Close() error {
+	var err error
+	for _, shard := range c.shards {
+		if e := shard.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Snapshot writes keys, values and expiration times of all items which have not
+// expired to w, encoding them by codec. If codec is nil, GobCodec is used.
+// See Cache.Snapshot for details.
+func (c *ShardedCache[K, V]) Snapshot(w io.Writer, codec Codec) error {
+	var entries []snapshotEntry[K, V]
+	for _, shard := range c.shards {
+		e, err := shard.snapshotEntries()
+		if err != nil {
+			return err
+		}
+		entries = append(entries, e...)
+	}
+	return encodeSnapshot(w, codec, entries)
+}
+
+// Restore reads a snapshot written by Snapshot from r, decoding it by codec,
+// and sets the items to the cache. If codec is nil, GobCodec is used.
+// See Cache.Restore for details.
+func (c *ShardedCache[K, V]) Restore(r io.Reader, codec Codec) error {
+	if c.shards[0].isClosed() {
+		return ErrClosed
+	}
+	entries, err := decodeSnapshot[K, V](r, codec, c.shards[0].clock.Now())
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		c.Set(e.Key, e.Value, e.itemOption())
+	}
+	return nil
+}
+
+// Stats returns a snapshot of the cache statistics summed over all shards.
+func (c *ShardedCache[K, V]) Stats() Stats {
+	var stats Stats
+	for _, shard := range c.shards {
+		s := shard.Stats()
+		stats.Hits += s.Hits
+		stats.Misses += s.Misses
+		stats.Loads += s.Loads
+		stats.LoadErrors += s.LoadErrors
+		stats.Expirations += s.Expirations
+		stats.Size += s.Size
+		for reason, n := range s.Evictions {
+			if stats.Evictions == nil {
+				stats.Evictions = make(map[EvictionReason]uint64, len(s.Evictions))
+			}
+			stats.Evictions[reason] += n
+		}
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+// ResetStats resets all counters of the cache statistics to zero.
+func (c *ShardedCache[K, V]) ResetStats() {
+	for _, shard := range c.shards {
+		shard.ResetStats()
+	}
+}
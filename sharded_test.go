@@ -0,0 +1,180 @@
+package cache_test
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	cache "github.com/Code-Hex/go-generics-cache"
+	"github.com/Code-Hex/go-generics-cache/policy/lru"
+)
+
+func TestShardedCache(t *testing.T) {
+	c := cache.NewSharded[int, int](4, cache.WithStats[int, int]())
+	for i := 0; i < 100; i++ {
+		c.Set(i, i*10)
+	}
+	if got := c.Len(); got != 100 {
+		t.Errorf("want %d items but got %d", 100, got)
+	}
+
+	keys := c.Keys()
+	sort.Ints(keys)
+	for i, key := range keys {
+		if i != key {
+			t.Fatalf("want key %d but got %d", i, key)
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		if v, ok := c.Get(i); v != i*10 || !ok {
+			t.Errorf("want %d, true but got %d, %v", i*10, v, ok)
+		}
+	}
+	if _, ok := c.Get(100); ok {
+		t.Errorf("want key 100 is not found")
+	}
+
+	c.Delete(1)
+	if c.Contains(1) {
+		t.Errorf("want key 1 is deleted")
+	}
+	if v, loaded := c.GetOrSet(1, 100); v != 100 || loaded {
+		t.Errorf("want 100, false but got %d, %v", v, loaded)
+	}
+	v, err := c.GetOrLoad(context.Background(), 200, func(ctx context.Context, key int) (int, error) {
+		return key, nil
+	})
+	if v != 200 || err != nil {
+		t.Errorf("want 200, nil but got %d, %v", v, err)
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 100 {
+		t.Errorf("want %d hits but got %d", 100, stats.Hits)
+	}
+	if stats.Size != 101 {
+		t.Errorf("want size %d but got %d", 101, stats.Size)
+	}
+}
+
+func TestShardedCacheEqualKeys(t *testing.T) {
+	type point struct{ x, y int }
+	pc := cache.NewSharded[*point, int](16)
+	keys := make([]*point, 100)
+	for i := range keys {
+		keys[i] = &point{x: i}
+		pc.Set(keys[i], i)
+	}
+	// the pointer keys are looked up by their address.
+	for i, key := range keys {
+		key.y = i + 1
+		if v, ok := pc.Get(key); v != i || !ok {
+			t.Fatalf("want %d, true but got %d, %v", i, v, ok)
+		}
+	}
+
+	fc := cache.NewSharded[float64, int](16)
+	fc.Set(0, 1)
+	if v, ok := fc.Get(math.Copysign(0, -1)); v != 1 || !ok {
+		t.Errorf("want -0 is found as 0, but got %d, %v", v, ok)
+	}
+}
+
+func TestShardedCacheDeleteExpired(t *testing.T) {
+	c := cache.NewSharded[int, int](4)
+	for i := 0; i < 10; i++ {
+		c.Set(i, i, cache.WithExpiration(time.Millisecond))
+	}
+	c.Set(10, 10)
+
+	time.Sleep(10 * time.Millisecond)
+	c.DeleteExpired()
+
+	if got := c.Len(); got != 1 {
+		t.Errorf("want %d items but got %d", 1, got)
+	}
+}
+
+func TestShardedCacheMultiThread(t *testing.T) {
+	c := cache.NewSharded(8, cache.AsLRU[int, int](lru.WithCapacity(10)))
+	var wg sync.WaitGroup
+	for i := int64(0); i < 100; i++ {
+		wg.Add(1)
+		go func(i int64) {
+			defer wg.Done()
+			m := rand.New(rand.NewSource(i))
+			for n := 0; n < 100; n++ {
+				key := m.Intn(100000)
+				c.Set(key, m.Intn(100000))
+				c.Get(key)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := c.Len(); got > 80 {
+		t.Errorf("want each shard holds at most 10 items but got %d items", got)
+	}
+}
+
+func TestShardedCacheSnapshotRestore(t *testing.T) {
+	src := cache.NewSharded[int, int](4)
+	for i := 0; i < 10; i++ {
+		src.Set(i, i)
+	}
+
+	var buf bytes.Buffer
+	if err := src.Snapshot(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// the snapshot is compatible with non-sharded Cache.
+	dst := cache.New[int, int]()
+	if err := dst.Restore(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if v, ok := dst.Get(i); v != i || !ok {
+			t.Errorf("want %d, true but got %d, %v", i, v, ok)
+		}
+	}
+}
+
+func TestShardedBulk(t *testing.T) {
+	c := cache.NewSharded[int, int](4)
+	items := make(map[int]int, 100)
+	for i := 0; i < 100; i++ {
+		items[i] = i * 10
+	}
+	c.SetMany(items)
+	if got := c.Len(); got != 100 {
+		t.Fatalf("want %d items but got %d", 100, got)
+	}
+
+	found, missing := c.GetMany([]int{100, 1, 2, 101, 3})
+	if len(found) != 3 || found[1] != 10 || found[2] != 20 || found[3] != 30 {
+		t.Errorf("want found 1, 2 and 3 but got %v", found)
+	}
+	if len(missing) != 2 || missing[0] != 100 || missing[1] != 101 {
+		t.Errorf("want missing [100 101] but got %v", missing)
+	}
+
+	keys := make([]int, 0, 60)
+	for i := 0; i < 60; i++ {
+		keys = append(keys, i)
+	}
+	deleted := c.DeleteMany(append(keys, 200))
+	sort.Ints(deleted)
+	if len(deleted) != 60 || deleted[0] != 0 || deleted[59] != 59 {
+		t.Errorf("want 0 to 59 are deleted but got %v", deleted)
+	}
+	if got := c.Len(); got != 40 {
+		t.Fatalf("want %d items but got %d", 40, got)
+	}
+}
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// errLoaderPanicked is returned to waiters when the loader they were
+// waiting for panicked.
+var errLoaderPanicked = errors.New("cache: loader panicked")
+
+// call is an in-flight or completed load.
+type call[V any] struct {
+	done chan struct{}
+	val  V
+	err  error
+	// panicked is the value recovered from the panic of the load.
+	panicked interface{}
+}
+
+// group deduplicates concurrent loads for the same key.
+// The zero value is ready to use.
+type group[K comparable, V any] struct {
+	mu    sync.Mutex
+	calls map[K]*call[V]
+}
+
+// do executes fn for key, making sure that only one execution is in-flight
+// for a given key at a time. If a duplicate comes in, the duplicate caller
+// waits for the original to complete and receives the same results.
+//
+// fn is executed in its own goroutine, so every caller including the one
+// which has started the execution gives up with ctx.Err() when its ctx is
+// done first, while fn keeps running for the other callers. If fn panics,
+// the panic is propagated to the caller which has started the execution if
+// it is still waiting, and the others receive errLoaderPanicked.
+func (g *group[K, V]) do(ctx context.Context, key K, fn func() (V, error)) (V, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[K]*call[V])
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		return c.wait(ctx, false)
+	}
+	c := &call[V]{
+		done: make(chan struct{}),
+		err:  errLoaderPanicked, // overwritten if fn returns normally
+	}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	go g.run(key, c, fn)
+	return c.wait(ctx, true)
+}
+
+func (g *group[K, V]) run(key K, c *call[V], fn func() (V, error)) {
+	defer func() {
+		c.panicked = recover()
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+	c.val, c.err = fn()
+}
+
+// wait waits for the call to complete or ctx to be done. owner reports
+// whether the caller has started the call.
+func (c *call[V]) wait(ctx context.Context, owner bool) (V, error) {
+	select {
+	case <-c.done:
+		if owner && c.panicked != nil {
+			panic(c.panicked)
+		}
+		return c.val, c.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
@@ -0,0 +1,214 @@
+package cache
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Code-Hex/go-generics-cache/policy/mru"
+)
+
+// Encoder encodes values to a stream.
+type Encoder interface {
+	Encode(v any) error
+}
+
+// Decoder decodes values from a stream.
+type Decoder interface {
+	Decode(v any) error
+}
+
+// Codec is used to encode and decode snapshots of the cache.
+type Codec interface {
+	NewEncoder(w io.Writer) Encoder
+	NewDecoder(r io.Reader) Decoder
+}
+
+var (
+	// GobCodec is a Codec which uses encoding/gob.
+	// This is the default Codec of Snapshot and Restore.
+	GobCodec Codec = gobCodec{}
+	// JSONCodec is a Codec which uses encoding/json.
+	JSONCodec Codec = jsonCodec{}
+)
+
+type gobCodec struct{}
+
+func (gobCodec) NewEncoder(w io.Writer) Encoder { return gob.NewEncoder(w) }
+func (gobCodec) NewDecoder(r io.Reader) Decoder { return gob.NewDecoder(r) }
+
+type jsonCodec struct{}
+
+func (jsonCodec) NewEncoder(w io.Writer) Encoder { return json.NewEncoder(w) }
+func (jsonCodec) NewDecoder(r io.Reader) Decoder { return json.NewDecoder(r) }
+
+// snapshotVersion is the version of the snapshot format.
+const snapshotVersion = 1
+
+type snapshotHeader struct {
+	Version int
+	Len     int
+}
+
+type snapshotEntry[K comparable, V any] struct {
+	Key               K
+	Value             V
+	Expiration        time.Time
+	SlidingExpiration time.Duration
+	MaxExpiration     time.Time
+	// StaleWhileRevalidate and StaleIfError are the grace periods after the
+	// expiration. See WithStaleWhileRevalidate and WithStaleIfError.
+	StaleWhileRevalidate time.Duration
+	StaleIfError         time.Duration
+}
+
+// itemOption returns an option which restores the expiration of the entry.
+// The sliding expiration restarts from the time of restore.
+func (e snapshotEntry[K, V]) itemOption() ItemOption {
+	return func(o *itemOptions) {
+		o.expiration = e.Expiration
+		o.slidingExpiration = e.SlidingExpiration
+		o.staleWhileRevalidate = e.StaleWhileRevalidate
+		o.staleIfError = e.StaleIfError
+		if e.SlidingExpiration > 0 {
+			o.expiration = e.MaxExpiration
+		}
+	}
+}
+
+// Snapshot writes keys, values and expiration times of all items which have not
+// expired to w, encoding them by codec. If codec is nil, GobCodec is used.
+//
+// The items are written in the order from the oldest to the newest, so
+// restoring the snapshot by Restore also restores the order of LRU, MRU and
+// FIFO policies. The state which is not reproduced by setting the items in
+// the order, e.g. the frequencies of LFU or the segments of ARC, is not restored.
+func (c *Cache[K, V]) Snapshot(w io.Writer, codec Codec) error {
+	entries, err := c.snapshotEntries()
+	if err != nil {
+		return err
+	}
+	return encodeSnapshot(w, codec, entries)
+}
+
+// Restore reads a snapshot written by Snapshot from r, decoding it by codec,
+// and sets the items to the cache. If codec is nil, GobCodec is used.
+// Items which have expired are skipped, and the sliding expiration of
+// items restarts from the time of restore.
+//
+// Nothing is restored if the snapshot cannot be decoded.
+func (c *Cache[K, V]) Restore(r io.Reader, codec Codec) error {
+	if c.isClosed() {
+		return ErrClosed
+	}
+	entries, err := decodeSnapshot[K, V](r, codec, c.clock.Now())
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		c.Set(e.Key, e.Value, e.itemOption())
+	}
+	return nil
+}
+
+func (c *Cache[K, V]) snapshotEntries() ([]snapshotEntry[K, V], error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, ErrClosed
+	}
+	keys := c.snapshotKeys()
+	entries := make([]snapshotEntry[K, V], 0, len(keys))
+	for _, key := range keys {
+		item, ok := c.cache.Peek(key)
+		if !ok || item.Expired() {
+			continue
+		}
+		entries = append(entries, snapshotEntry[K, V]{
+			Key:                  key,
+			Value:                item.Value,
+			Expiration:           item.Expiration,
+			SlidingExpiration:    item.SlidingExpiration,
+			MaxExpiration:        item.MaxExpiration,
+			StaleWhileRevalidate: item.StaleWhileRevalidate,
+			StaleIfError:         item.StaleIfError,
+		})
+	}
+	return entries, nil
+}
+
+// snapshotKeys returns the keys of the cache in the order from the oldest to
+// the newest, so that setting the items in the order reproduces the order of
+// the policy. c.mu must be held.
+func (c *Cache[K, V]) snapshotKeys() []K {
+	keys := c.cache.Keys()
+	if _, ok := c.cache.(*mru.Cache[K, *Item[K, V]]); ok {
+		// the keys of MRU are in the order from the newest.
+		for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+			keys[i], keys[j] = keys[j], keys[i]
+		}
+	}
+	return keys
+}
+
+func encodeSnapshot[K comparable, V any](w io.Writer, codec Codec, entries []snapshotEntry[K, V]) error {
+	if codec == nil {
+		codec = GobCodec
+	}
+	enc := codec.NewEncoder(w)
+	header := snapshotHeader{
+		Version: snapshotVersion,
+		Len:     len(entries),
+	}
+	if err := enc.Encode(header); err != nil {
+		return fmt.Errorf("failed to encode snapshot header: %w", err)
+	}
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("failed to encode snapshot entry: %w", err)
+		}
+	}
+	return nil
+}
+
+// maxSnapshotPrealloc is the maximum number of entries preallocated
+// by decodeSnapshot.
+const maxSnapshotPrealloc = 1024
+
+// decodeSnapshot decodes the snapshot, and returns entries which have not expired at now.
+func decodeSnapshot[K comparable, V any](r io.Reader, codec Codec, now time.Time) ([]snapshotEntry[K, V], error) {
+	if codec == nil {
+		codec = GobCodec
+	}
+	dec := codec.NewDecoder(r)
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot header: %w", err)
+	}
+	if header.Version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version: %d", header.Version)
+	}
+	if header.Len < 0 {
+		return nil, fmt.Errorf("invalid snapshot length: %d", header.Len)
+	}
+	// the length is not trusted for the allocation, since the snapshot may be
+	// corrupted. the entries are appended beyond the preallocated capacity.
+	n := header.Len
+	if n > maxSnapshotPrealloc {
+		n = maxSnapshotPrealloc
+	}
+	entries := make([]snapshotEntry[K, V], 0, n)
+	for i := 0; i < header.Len; i++ {
+		var e snapshotEntry[K, V]
+		if err := dec.Decode(&e); err != nil {
+			return nil, fmt.Errorf("failed to decode snapshot entry: %w", err)
+		}
+		if !e.Expiration.IsZero() && now.After(e.Expiration) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
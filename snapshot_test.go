@@ -0,0 +1,128 @@
+package cache_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	cache "github.com/Code-Hex/go-generics-cache"
+	"github.com/Code-Hex/go-generics-cache/policy/lru"
+	"github.com/Code-Hex/go-generics-cache/policy/mru"
+)
+
+type snapshotValue struct {
+	Name string
+	Age  int
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	codecs := []struct {
+		name  string
+		codec cache.Codec
+	}{
+		{name: "default", codec: nil},
+		{name: "gob", codec: cache.GobCodec},
+		{name: "json", codec: cache.JSONCodec},
+	}
+	for _, tc := range codecs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			src := cache.New(cache.AsLRU[string, snapshotValue](lru.WithCapacity(10)))
+			src.Set("a", snapshotValue{Name: "a", Age: 1})
+			src.Set("b", snapshotValue{Name: "b", Age: 2}, cache.WithExpiration(time.Hour))
+			src.Set("c", snapshotValue{Name: "c", Age: 3})
+			src.Set("d", snapshotValue{Name: "d", Age: 4}, cache.WithSlidingExpiration(time.Hour))
+			src.Get("a") // "a" is the most recently used
+
+			var buf bytes.Buffer
+			if err := src.Snapshot(&buf, tc.codec); err != nil {
+				t.Fatal(err)
+			}
+
+			dst := cache.New(cache.AsLRU[string, snapshotValue](lru.WithCapacity(10)))
+			if err := dst.Restore(&buf, tc.codec); err != nil {
+				t.Fatal(err)
+			}
+
+			if want, got := src.Keys(), dst.Keys(); !reflect.DeepEqual(want, got) {
+				t.Errorf("want keys %v but got %v", want, got)
+			}
+			for _, key := range src.Keys() {
+				want, _ := src.Get(key)
+				got, ok := dst.Get(key)
+				if !ok || want != got {
+					t.Errorf("want %v for key %q but got %v, %v", want, key, got, ok)
+				}
+			}
+		})
+	}
+}
+
+func TestSnapshotRestoreMRU(t *testing.T) {
+	src := cache.New(cache.AsMRU[string, int](mru.WithCapacity(10)))
+	src.Set("a", 1)
+	src.Set("b", 2)
+	src.Set("c", 3)
+	src.Get("a") // "a" is the most recently used
+
+	var buf bytes.Buffer
+	if err := src.Snapshot(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	dst := cache.New(cache.AsMRU[string, int](mru.WithCapacity(10)))
+	if err := dst.Restore(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := src.Keys(), dst.Keys(); !reflect.DeepEqual(want, got) {
+		t.Errorf("want keys %v but got %v", want, got)
+	}
+}
+
+func TestRestoreSkipsExpired(t *testing.T) {
+	src := cache.New[string, int]()
+	src.Set("a", 1)
+	src.Set("b", 2, cache.WithExpiration(10*time.Millisecond))
+	src.Set("c", 3, cache.WithExpiration(time.Hour))
+
+	var buf bytes.Buffer
+	if err := src.Snapshot(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	dst := cache.New[string, int]()
+	if err := dst.Restore(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := []string{"a", "c"}, dst.Keys(); !reflect.DeepEqual(want, got) {
+		t.Errorf("want keys %v but got %v", want, got)
+	}
+}
+
+func TestRestoreInvalidSnapshot(t *testing.T) {
+	c := cache.New[string, int]()
+	err := c.Restore(bytes.NewBufferString("invalid"), cache.JSONCodec)
+	if err == nil {
+		t.Fatal("want error")
+	}
+	if got := c.Len(); got != 0 {
+		t.Errorf("want nothing is restored but got %d items", got)
+	}
+}
+
+func TestRestoreInvalidLength(t *testing.T) {
+	for _, input := range []string{
+		`{"Version":1,"Len":-1}`,
+		`{"Version":1,"Len":9223372036854775807}`,
+	} {
+		c := cache.New[string, int]()
+		if err := c.Restore(bytes.NewBufferString(input), cache.JSONCodec); err == nil {
+			t.Errorf("want error for %s", input)
+		}
+		if got := c.Len(); got != 0 {
+			t.Errorf("want nothing is restored but got %d items", got)
+		}
+	}
+}
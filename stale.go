@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// WithStaleWhileRevalidate is an option to set the grace period after the
+// expiration of the item. During the period, GetOrLoad and GetOrLoadStale
+// return the expired value immediately while reloading it in the background.
+// Errors of the reload are reported to the callback set by OnRefreshError.
+//
+// The item is not deleted by the janitor until the grace period ends,
+// but Get reports the expired item is not found as before.
+// If the duration is zero or negative value, it treats as w/o grace period.
+func WithStaleWhileRevalidate(d time.Duration) ItemOption {
+	if d <= 0 {
+		return func(o *itemOptions) {}
+	}
+	return func(o *itemOptions) {
+		o.staleWhileRevalidate = d
+	}
+}
+
+// WithStaleIfError is an option to set the period after the expiration of the
+// item during which GetOrLoad and GetOrLoadStale fall back to the expired
+// value if the loader returns an error.
+//
+// The item is not deleted by the janitor until the period ends,
+// but Get reports the expired item is not found as before.
+// If the duration is zero or negative value, it treats as w/o the fallback.
+func WithStaleIfError(d time.Duration) ItemOption {
+	if d <= 0 {
+		return func(o *itemOptions) {}
+	}
+	return func(o *itemOptions) {
+		o.staleIfError = d
+	}
+}
+
+// GetOrLoadStale is like GetOrLoad, but also reports whether the returned
+// value is stale, that is, the value has expired and is served within the
+// grace period set by WithStaleWhileRevalidate or WithStaleIfError.
+//
+// Within the stale-while-revalidate period, the stale value is returned
+// without waiting for the loader, which is called in the background.
+// Otherwise the loader is called as GetOrLoad does, and if it returns an
+// error within the stale-if-error period, the stale value is returned
+// without the error.
+func (c *Cache[K, V]) GetOrLoadStale(ctx context.Context, key K, loader LoaderFunc[K, V], opts ...ItemOption) (value V, stale bool, err error) {
+	if loader == nil {
+		loader = c.loader
+	}
+	if loader == nil {
+		return value, false, ErrNoLoader
+	}
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return value, false, ErrClosed
+	}
+	item, ok := c.cache.Get(key)
+	if ok && !item.Expired() {
+		c.touch(key, item)
+		c.refreshAheadIfNeeded(key, item)
+		c.stats.lookup(true)
+		c.mu.Unlock()
+		return item.Value, false, nil
+	}
+	if ok && item.withinGrace(item.StaleWhileRevalidate) {
+		c.reloadInBackground(key, item, loader, opts)
+		c.stats.lookup(true)
+		c.mu.Unlock()
+		return item.Value, true, nil
+	}
+	var fallback *Item[K, V]
+	if ok && item.withinGrace(item.StaleIfError) {
+		fallback = item
+	}
+	c.stats.lookup(false)
+	c.mu.Unlock()
+
+	value, err = c.load(ctx, key, loader, opts)
+	if err != nil && fallback != nil {
+		return fallback.Value, true, nil
+	}
+	return value, false, err
+}
@@ -0,0 +1,183 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cache "github.com/Code-Hex/go-generics-cache"
+	"github.com/Code-Hex/go-generics-cache/cachetest"
+)
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	clk := cachetest.NewFakeClock(time.Now())
+	release := make(chan struct{})
+	c := cache.New(
+		cache.WithClock[string, int](clk),
+		cache.WithJanitorInterval[string, int](time.Second),
+	)
+	defer c.Close()
+
+	loader := func(ctx context.Context, key string) (int, error) {
+		<-release
+		return 2, nil
+	}
+	opts := []cache.ItemOption{
+		cache.WithExpiration(time.Minute),
+		cache.WithStaleWhileRevalidate(30 * time.Second),
+	}
+	c.Set("a", 1, opts...)
+
+	// the janitor does not delete the item within the grace period.
+	clk.Advance(70 * time.Second)
+	if got := c.Len(); got != 1 {
+		t.Fatalf("want 1 item but got %d", got)
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("want Get does not return the stale value")
+	}
+
+	for i := 0; i < 3; i++ {
+		got, stale, err := c.GetOrLoadStale(context.Background(), "a", loader, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 1 || !stale {
+			t.Fatalf("want stale value 1 but got %d, %v", got, stale)
+		}
+	}
+	close(release)
+
+	waitFor(t, func() bool {
+		got, ok := c.Get("a")
+		return ok && got == 2
+	})
+	got, stale, err := c.GetOrLoadStale(context.Background(), "a", loader, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 2 || stale {
+		t.Fatalf("want fresh value 2 but got %d, %v", got, stale)
+	}
+}
+
+func TestStaleWhileRevalidateExpired(t *testing.T) {
+	clk := cachetest.NewFakeClock(time.Now())
+	var evicted []string
+	c := cache.New(
+		cache.WithClock[string, int](clk),
+		cache.WithJanitorInterval[string, int](time.Second),
+		cache.OnEvicted(func(key string, _ int, reason cache.EvictionReason) {
+			evicted = append(evicted, key+":"+reason.String())
+		}),
+	)
+	defer c.Close()
+
+	c.Set("a", 1,
+		cache.WithExpiration(time.Minute),
+		cache.WithStaleWhileRevalidate(30*time.Second),
+	)
+
+	// the janitor deletes the item after the grace period.
+	clk.Advance(91 * time.Second)
+	if got := c.Len(); got != 0 {
+		t.Fatalf("want no items but got %d", got)
+	}
+	if len(evicted) != 1 || evicted[0] != "a:expired" {
+		t.Fatalf("want a is expired but got %v", evicted)
+	}
+
+	got, stale, err := c.GetOrLoadStale(context.Background(), "a", func(ctx context.Context, key string) (int, error) {
+		return 2, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 2 || stale {
+		t.Fatalf("want loaded value 2 but got %d, %v", got, stale)
+	}
+}
+
+func TestStaleIfError(t *testing.T) {
+	clk := cachetest.NewFakeClock(time.Now())
+	c := cache.New(cache.WithClock[string, int](clk))
+	defer c.Close()
+
+	wantErr := errors.New("error")
+	loader := func(ctx context.Context, key string) (int, error) {
+		return 0, wantErr
+	}
+	c.Set("a", 1,
+		cache.WithExpiration(time.Minute),
+		cache.WithStaleIfError(time.Minute),
+	)
+
+	clk.Advance(90 * time.Second)
+	got, stale, err := c.GetOrLoadStale(context.Background(), "a", loader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 1 || !stale {
+		t.Fatalf("want stale value 1 but got %d, %v", got, stale)
+	}
+	if got, err := c.GetOrLoad(context.Background(), "a", loader); got != 1 || err != nil {
+		t.Fatalf("want stale value 1 but got %d, %v", got, err)
+	}
+
+	// the stale-if-error period has passed.
+	clk.Advance(time.Minute)
+	if _, _, err := c.GetOrLoadStale(context.Background(), "a", loader); !errors.Is(err, wantErr) {
+		t.Fatalf("want %v but got %v", wantErr, err)
+	}
+
+	// the loaded value replaces the stale value.
+	got, stale, err = c.GetOrLoadStale(context.Background(), "a", func(ctx context.Context, key string) (int, error) {
+		return 2, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 2 || stale {
+		t.Fatalf("want loaded value 2 but got %d, %v", got, stale)
+	}
+}
+
+func TestShardedStaleIfError(t *testing.T) {
+	clk := cachetest.NewFakeClock(time.Now())
+	c := cache.NewSharded(4, cache.WithClock[int, int](clk))
+	defer c.Close()
+
+	wantErr := errors.New("error")
+	loader := func(ctx context.Context, key int) (int, error) {
+		return 0, wantErr
+	}
+	for i := 0; i < 10; i++ {
+		c.Set(i, i,
+			cache.WithExpiration(time.Minute),
+			cache.WithStaleIfError(time.Minute),
+		)
+	}
+
+	clk.Advance(90 * time.Second)
+	for i := 0; i < 10; i++ {
+		got, stale, err := c.GetOrLoadStale(context.Background(), i, loader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != i || !stale {
+			t.Fatalf("want stale value %d but got %d, %v", i, got, stale)
+		}
+	}
+
+	// the key which is not in the cache is loaded.
+	got, stale, err := c.GetOrLoadStale(context.Background(), 10, func(ctx context.Context, key int) (int, error) {
+		return key * 2, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 20 || stale {
+		t.Fatalf("want loaded value 20 but got %d, %v", got, stale)
+	}
+}
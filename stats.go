@@ -0,0 +1,118 @@
+package cache
+
+import "sync/atomic"
+
+// Stats is a snapshot of the cache statistics.
+type Stats struct {
+	// Hits is the number of lookups which found a value.
+	Hits uint64
+	// Misses is the number of lookups which did not find a value.
+	Misses uint64
+	// Loads is the number of values successfully loaded by GetOrLoad
+	// or reloaded by refresh-ahead.
+	Loads uint64
+	// LoadErrors is the number of loads which returned an error.
+	LoadErrors uint64
+	// Evictions is the number of entries removed from the cache by reason.
+	// Entries removed by expiration are reported as Expirations instead.
+	Evictions map[EvictionReason]uint64
+	// Expirations is the number of entries removed because they have expired.
+	Expirations uint64
+	// Size is the number of items currently in the cache.
+	Size int
+	// HitRatio is the ratio of Hits to the total number of lookups.
+	// It is 0 if there have been no lookups.
+	HitRatio float64
+}
+
+// statsCounter counts cache statistics atomically.
+// All methods are no-op on a nil *statsCounter.
+type statsCounter struct {
+	hits       uint64
+	misses     uint64
+	loads      uint64
+	loadErrors uint64
+	evictions  [EvictionReasonReplaced + 1]uint64
+}
+
+func (s *statsCounter) lookup(hit bool) {
+	if s == nil {
+		return
+	}
+	if hit {
+		atomic.AddUint64(&s.hits, 1)
+	} else {
+		atomic.AddUint64(&s.misses, 1)
+	}
+}
+
+func (s *statsCounter) load(err error) {
+	if s == nil {
+		return
+	}
+	if err != nil {
+		atomic.AddUint64(&s.loadErrors, 1)
+	} else {
+		atomic.AddUint64(&s.loads, 1)
+	}
+}
+
+func (s *statsCounter) evicted(reason EvictionReason) {
+	if s == nil {
+		return
+	}
+	atomic.AddUint64(&s.evictions[reason], 1)
+}
+
+func (s *statsCounter) snapshot() Stats {
+	if s == nil {
+		return Stats{}
+	}
+	stats := Stats{
+		Hits:        atomic.LoadUint64(&s.hits),
+		Misses:      atomic.LoadUint64(&s.misses),
+		Loads:       atomic.LoadUint64(&s.loads),
+		LoadErrors:  atomic.LoadUint64(&s.loadErrors),
+		Evictions:   make(map[EvictionReason]uint64, len(s.evictions)),
+		Expirations: atomic.LoadUint64(&s.evictions[EvictionReasonExpired]),
+	}
+	for _, reason := range []EvictionReason{
+		EvictionReasonCapacity,
+		EvictionReasonDeleted,
+		EvictionReasonReplaced,
+	} {
+		stats.Evictions[reason] = atomic.LoadUint64(&s.evictions[reason])
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+func (s *statsCounter) reset() {
+	if s == nil {
+		return
+	}
+	atomic.StoreUint64(&s.hits, 0)
+	atomic.StoreUint64(&s.misses, 0)
+	atomic.StoreUint64(&s.loads, 0)
+	atomic.StoreUint64(&s.loadErrors, 0)
+	for i := range s.evictions {
+		atomic.StoreUint64(&s.evictions[i], 0)
+	}
+}
+
+// Stats returns a snapshot of the cache statistics.
+//
+// The counters are reported only if the cache is created with WithStats option.
+// Otherwise, only Size is reported.
+func (c *Cache[K, V]) Stats() Stats {
+	stats := c.stats.snapshot()
+	stats.Size = c.Len()
+	return stats
+}
+
+// ResetStats resets all counters of the cache statistics to zero.
+func (c *Cache[K, V]) ResetStats() {
+	c.stats.reset()
+}
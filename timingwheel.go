@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"container/list"
+	"time"
+)
+
+const (
+	wheelBits   = 6
+	wheelSize   = 1 << wheelBits
+	wheelMask   = wheelSize - 1
+	wheelLevels = 5
+)
+
+// timingWheel is an expirationTracker using a hierarchical timing wheel.
+//
+// The wheel has wheelLevels levels of wheelSize slots. A slot of level 0 holds
+// keys which expire at a tick, and a slot of level n holds keys which expire
+// within wheelSize^n ticks. Keys in a slot of the upper level are cascaded
+// to the lower levels when the wheel reaches the slot. Both update and remove
+// are O(1), and keys are expired within a tick after their expiration time.
+type timingWheel[K comparable] struct {
+	tick  time.Duration
+	start time.Time
+	// current is the number of ticks which have been processed since start.
+	current int64
+	slots   [wheelLevels][wheelSize]*list.List
+	// counts is the number of timers in each level.
+	counts  [wheelLevels]int
+	mapping map[K]*wheelTimer[K]
+}
+
+type wheelTimer[K comparable] struct {
+	key      K
+	deadline int64 // in ticks since start
+	level    int
+	slot     *list.List
+	elem     *list.Element
+}
+
+func newTimingWheel[K comparable](tick time.Duration, start time.Time) *timingWheel[K] {
+	w := &timingWheel[K]{
+		tick:    tick,
+		start:   start,
+		mapping: make(map[K]*wheelTimer[K]),
+	}
+	for level := range w.slots {
+		for i := range w.slots[level] {
+			w.slots[level][i] = list.New()
+		}
+	}
+	return w
+}
+
+func (w *timingWheel[K]) update(key K, expiration time.Time) {
+	t, ok := w.mapping[key]
+	if ok {
+		w.unlink(t)
+	} else {
+		t = &wheelTimer[K]{key: key}
+		w.mapping[key] = t
+	}
+	// the key expires at the first tick after the expiration time.
+	t.deadline = w.ticks(expiration) + 1
+	if t.deadline <= w.current {
+		t.deadline = w.current + 1
+	}
+	w.schedule(t)
+}
+
+func (w *timingWheel[K]) updateMany(keys []K, expirations []time.Time) {
+	for i, key := range keys {
+		w.update(key, expirations[i])
+	}
+}
+
+func (w *timingWheel[K]) remove(key K) {
+	if t, ok := w.mapping[key]; ok {
+		w.unlink(t)
+		delete(w.mapping, key)
+	}
+}
+
+func (w *timingWheel[K]) clear() {
+	for level := range w.slots {
+		for i := range w.slots[level] {
+			w.slots[level][i].Init()
+		}
+	}
+	w.counts = [wheelLevels]int{}
+	w.mapping = make(map[K]*wheelTimer[K])
+}
+
+func (w *timingWheel[K]) len() int {
+	return len(w.mapping)
+}
+
+func (w *timingWheel[K]) expired(now time.Time) []K {
+	target := w.ticks(now)
+	var keys []K
+	for w.current < target {
+		if len(w.mapping) == 0 {
+			// nothing to do until the target.
+			w.current = target
+			break
+		}
+		w.skip(target)
+		w.current++
+		w.cascade()
+		slot := w.slots[0][w.current&wheelMask]
+		for e := slot.Front(); e != nil; e = e.Next() {
+			key := e.Value.(*wheelTimer[K]).key
+			delete(w.mapping, key)
+			keys = append(keys, key)
+		}
+		w.counts[0] -= slot.Len()
+		slot.Init()
+	}
+	return keys
+}
+
+// skip advances the current tick up to just before the target, without passing
+// any tick which has to process timers. Ticks are skipped while the lower levels
+// have no timers, until the next tick to cascade the lowest non-empty level.
+// The target tick itself is left to be processed by the caller.
+func (w *timingWheel[K]) skip(target int64) {
+	level := 0
+	for level < wheelLevels-1 && w.counts[level] == 0 {
+		level++
+	}
+	if level == 0 {
+		return
+	}
+	next := w.current | (1<<(wheelBits*level) - 1)
+	if next >= target {
+		next = target - 1
+	}
+	w.current = next
+}
+
+// ticks returns the number of whole ticks from the start to t.
+func (w *timingWheel[K]) ticks(t time.Time) int64 {
+	d := t.Sub(w.start)
+	if d < 0 {
+		return 0
+	}
+	return int64(d / w.tick)
+}
+
+// schedule puts the timer into the slot for its deadline, which must not be
+// before the current tick.
+func (w *timingWheel[K]) schedule(t *wheelTimer[K]) {
+	delta := t.deadline - w.current
+	for level := 0; level < wheelLevels; level++ {
+		shift := wheelBits * level
+		if delta < 1<<(shift+wheelBits) {
+			w.push(t, level, (t.deadline>>shift)&wheelMask)
+			return
+		}
+	}
+	// too far in the future. puts it into the last slot of the top level,
+	// and it will be scheduled again when the slot is cascaded.
+	shift := wheelBits * (wheelLevels - 1)
+	w.push(t, wheelLevels-1, ((w.current>>shift)-1)&wheelMask)
+}
+
+func (w *timingWheel[K]) push(t *wheelTimer[K], level int, index int64) {
+	t.level = level
+	t.slot = w.slots[level][index]
+	t.elem = t.slot.PushBack(t)
+	w.counts[level]++
+}
+
+func (w *timingWheel[K]) unlink(t *wheelTimer[K]) {
+	t.slot.Remove(t.elem)
+	w.counts[t.level]--
+}
+
+// cascade moves timers in the upper level slots which the current tick
+// has reached to the lower levels.
+func (w *timingWheel[K]) cascade() {
+	for level := wheelLevels - 1; level > 0; level-- {
+		shift := wheelBits * level
+		if w.current&(1<<shift-1) != 0 {
+			continue
+		}
+		slot := w.slots[level][(w.current>>shift)&wheelMask]
+		for e := slot.Front(); e != nil; {
+			next := e.Next()
+			t := e.Value.(*wheelTimer[K])
+			w.unlink(t)
+			w.schedule(t)
+			e = next
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/Code-Hex/go-generics-cache/cachetest"
+)
+
+func TestTimingWheel(t *testing.T) {
+	start := time.Now()
+	tick := time.Millisecond
+	w := newTimingWheel[int](tick, start)
+
+	rnd := rand.New(rand.NewSource(1))
+	durations := []time.Duration{
+		10 * time.Millisecond,
+		100 * time.Millisecond,
+		time.Second,
+		time.Minute,
+		time.Hour,
+		24 * time.Hour,
+		30 * 24 * time.Hour, // beyond the span of the wheel
+	}
+	want := make(map[int]time.Time)
+	now := start
+	for i := 0; i < 20000; i++ {
+		key := rnd.Intn(1000)
+		switch rnd.Intn(10) {
+		case 0:
+			w.remove(key)
+			delete(want, key)
+		case 1, 2:
+			now = now.Add(time.Duration(rnd.Int63n(int64(durations[rnd.Intn(len(durations))]))))
+			// the cache does not call expired while the wheel is empty,
+			// so the current tick of the wheel can be left behind.
+			if w.len() == 0 {
+				break
+			}
+			for _, key := range w.expired(now) {
+				exp, ok := want[key]
+				if !ok {
+					t.Fatalf("step %d: unknown key %d is expired", i, key)
+				}
+				if !now.After(exp) {
+					t.Fatalf("step %d: key %d is expired before its expiration", i, key)
+				}
+				delete(want, key)
+			}
+			for key, exp := range want {
+				if now.After(exp.Add(tick)) {
+					t.Fatalf("step %d: key %d is not expired %v after its expiration", i, key, now.Sub(exp))
+				}
+			}
+		default:
+			d := durations[rnd.Intn(len(durations))]
+			exp := now.Add(time.Duration(rnd.Int63n(int64(d))))
+			w.update(key, exp)
+			want[key] = exp
+		}
+
+		if got := w.len(); got != len(want) {
+			t.Fatalf("step %d: want length %d but got %d", i, len(want), got)
+		}
+		total := 0
+		for _, n := range w.counts {
+			total += n
+		}
+		if total != len(want) {
+			t.Fatalf("step %d: want %d timers in the wheel but got %d", i, len(want), total)
+		}
+	}
+}
+
+func TestCacheWithTimingWheel(t *testing.T) {
+	clk := cachetest.NewFakeClock(time.Now())
+	c := New(
+		WithClock[string, int](clk),
+		WithTimingWheel[string, int](10*time.Millisecond),
+	)
+	c.Set("a", 1, WithExpiration(30*time.Millisecond))
+	c.Set("b", 2, WithExpiration(time.Hour))
+	c.Set("c", 3)
+
+	clk.Advance(40 * time.Millisecond)
+
+	// "a" has been deleted by the janitor.
+	c.mu.Lock()
+	_, ok := c.cache.Get("a")
+	c.mu.Unlock()
+	if ok {
+		t.Fatal("want a is deleted")
+	}
+	if got := c.Len(); got != 2 {
+		t.Fatalf("want length 2 but got %d", got)
+	}
+}